@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func runUpload(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("upload")
+	var f transferFlags
+	f.register(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return fmt.Errorf("upload needs at least one source and a destination")
+	}
+	srcs, dst := fset.Args()[:fset.NArg()-1], trimRemote(fset.Arg(fset.NArg()-1))
+	return upload(ctx, c, srcs, dst, f)
+}
+
+func runDownload(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("download")
+	var f transferFlags
+	f.register(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return fmt.Errorf("download needs at least one source and a destination")
+	}
+	return download(ctx, c, fset.Args()[:fset.NArg()-1], fset.Arg(fset.NArg()-1), f)
+}
+
+func runCp(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("cp")
+	var f transferFlags
+	f.register(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() < 2 {
+		fset.Usage()
+		return fmt.Errorf("cp needs at least one source and a destination")
+	}
+	srcs, dst := fset.Args()[:fset.NArg()-1], fset.Arg(fset.NArg()-1)
+	remoteSrc := isRemote(srcs[0])
+	for _, s := range srcs[1:] {
+		if isRemote(s) != remoteSrc {
+			return fmt.Errorf("sources must be all local or all %s paths", remotePrefix)
+		}
+	}
+
+	switch {
+	case !remoteSrc && isRemote(dst):
+		return upload(ctx, c, srcs, trimRemote(dst), f)
+	case remoteSrc && !isRemote(dst):
+		return download(ctx, c, srcs, dst, f)
+	case remoteSrc && isRemote(dst):
+		jobs, err := planCopy(ctx, c, srcs, trimRemote(dst), f.recursive)
+		if err != nil {
+			return err
+		}
+		p := newProgress(f.quiet)
+		return runJobs(ctx, jobs, f.parallel, p, func(ctx context.Context, j job) error {
+			return copyRemote(ctx, c, j, f.noClobber, p)
+		})
+	}
+	return fmt.Errorf("at least one side of cp must be an %s path", remotePrefix)
+}
+
+func upload(ctx context.Context, c *client, args []string, dst string, f transferFlags) error {
+	srcs, err := expandLocal(args)
+	if err != nil {
+		return err
+	}
+	jobs, err := planUpload(srcs, dst, f.recursive)
+	if err != nil {
+		return err
+	}
+	p := newProgress(f.quiet)
+	return runJobs(ctx, jobs, f.parallel, p, func(ctx context.Context, j job) error {
+		_, err := uploadFile(ctx, c, j, f.noClobber, p)
+		return err
+	})
+}
+
+func download(ctx context.Context, c *client, srcs []string, dst string, f transferFlags) error {
+	jobs, err := planDownload(ctx, c, srcs, dst, f.recursive)
+	if err != nil {
+		return err
+	}
+	p := newProgress(f.quiet)
+	return runJobs(ctx, jobs, f.parallel, p, func(ctx context.Context, j job) error {
+		return downloadFile(ctx, c, j, f.noClobber, p)
+	})
+}
+
+func runLs(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("ls")
+	long := fset.Bool("l", false, "long listing with size and modification time")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() > 1 {
+		fset.Usage()
+		return fmt.Errorf("ls takes at most one prefix or pattern")
+	}
+	filter := trimRemote(fset.Arg(0))
+
+	resp, err := c.ListFiles(ctx, &pb.ListFilesRequest{})
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+	files := resp.GetFiles()
+	sort.Slice(files, func(i, j int) bool { return files[i].GetName() < files[j].GetName() })
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	for _, file := range files {
+		name := file.GetName()
+		if hasMeta(filter) {
+			if ok, _ := path.Match(filter, name); !ok {
+				continue
+			}
+		} else if !strings.HasPrefix(name, filter) {
+			continue
+		}
+		if !*long {
+			fmt.Println(name)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t  %s\t  %s\t\n", file.GetSize(),
+			file.GetUpdatedAt().AsTime().Local().Format(time.DateTime), name)
+	}
+	return tw.Flush()
+}
+
+func runStat(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("stat")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return fmt.Errorf("stat needs at least one file")
+	}
+
+	stream, err := c.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	for i, name := range fset.Args() {
+		op := &pb.BatchRequest_Stat{Stat: &pb.StatOp{Filename: trimRemote(name)}}
+		if err := stream.Send(&pb.BatchRequest{Id: strconv.Itoa(i), Op: op}); err != nil {
+			return err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	results := make([]*pb.BatchResponse, fset.NArg())
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if i, err := strconv.Atoi(resp.GetId()); err == nil && i >= 0 && i < len(results) {
+			results[i] = resp
+		}
+	}
+
+	failed := 0
+	for i, resp := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		if resp == nil || resp.GetCode() != 0 {
+			failed++
+			fmt.Fprintf(os.Stderr, "filectl: stat %s: %s\n", fset.Arg(i), resp.GetError())
+			continue
+		}
+		printStat(resp.GetStat())
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be read", failed, len(results))
+	}
+	return nil
+}
+
+func printStat(m *pb.FileMetadata) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 1, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "name:\t%s\n", m.GetName())
+	size := strconv.FormatInt(m.GetSize(), 10)
+	if m.GetCompression() != "" {
+		size += fmt.Sprintf(" (stored %d, %s)", m.GetStoredSize(), m.GetCompression())
+	}
+	fmt.Fprintf(tw, "size:\t%s\n", size)
+	fmt.Fprintf(tw, "etag:\t%s\n", m.GetEtag())
+	if m.GetDigest() != "" {
+		fmt.Fprintf(tw, "digest:\t%s\n", m.GetDigest())
+	}
+	fmt.Fprintf(tw, "created:\t%s\n", m.GetCreatedAt().AsTime().Local().Format(time.RFC3339))
+	fmt.Fprintf(tw, "updated:\t%s\n", m.GetUpdatedAt().AsTime().Local().Format(time.RFC3339))
+	if m.GetExpiresAt() != nil {
+		fmt.Fprintf(tw, "expires:\t%s\n", m.GetExpiresAt().AsTime().Local().Format(time.RFC3339))
+	}
+	if img := m.GetImageInfo(); img != nil {
+		fmt.Fprintf(tw, "image:\t%s %dx%d\n", img.GetFormat(), img.GetWidth(), img.GetHeight())
+	}
+	keys := make([]string, 0, len(m.GetAttributes()))
+	for k := range m.GetAttributes() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "attr %s:\t%s\n", k, m.GetAttributes()[k])
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+	"time"
+)
+
+type connOptions struct {
+	addr       string
+	useTLS     bool
+	caCert     string
+	cert       string
+	key        string
+	serverName string
+	skipVerify bool
+	token      string
+	timeout    time.Duration
+}
+
+func (o *connOptions) register(fset *flag.FlagSet) {
+	fset.StringVar(&o.addr, "addr", envOr("FILECTL_ADDR", "localhost:8000"), "server address (env FILECTL_ADDR)")
+	fset.BoolVar(&o.useTLS, "tls", false, "connect with TLS")
+	fset.StringVar(&o.caCert, "ca-cert", "", "PEM file with CA certificates to verify the server (implies -tls)")
+	fset.StringVar(&o.cert, "cert", "", "PEM client certificate for mutual TLS (implies -tls)")
+	fset.StringVar(&o.key, "key", "", "PEM client key for mutual TLS")
+	fset.StringVar(&o.serverName, "server-name", "", "override the TLS server name")
+	fset.BoolVar(&o.skipVerify, "insecure-skip-verify", false, "do not verify the server certificate")
+	fset.StringVar(&o.token, "token", os.Getenv("FILECTL_TOKEN"), "bearer token sent with every call (env FILECTL_TOKEN)")
+	fset.DurationVar(&o.timeout, "timeout", 0, "overall command timeout, 0 for none")
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+type client struct {
+	pb.FileServiceClient
+	conn *grpc.ClientConn
+}
+
+func (c *client) Close() error {
+	return c.conn.Close()
+}
+
+func dial(o connOptions) (*client, error) {
+	creds := insecure.NewCredentials()
+	if o.useTLS || o.caCert != "" || o.cert != "" || o.skipVerify {
+		cfg, err := o.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(cfg)
+	}
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if o.token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerToken(o.token)))
+	}
+
+	conn, err := grpc.NewClient(o.addr, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", o.addr, err)
+	}
+	return &client{FileServiceClient: pb.NewFileServiceClient(conn), conn: conn}, nil
+}
+
+func (o connOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         o.serverName,
+		InsecureSkipVerify: o.skipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if o.caCert != "" {
+		pem, err := os.ReadFile(o.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.caCert)
+		}
+		cfg.RootCAs = pool
+	}
+	if o.cert != "" || o.key != "" {
+		pair, err := tls.LoadX509KeyPair(o.cert, o.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+// bearerToken sends an "authorization: Bearer" header with every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false so that tokens also work against local
+// servers without TLS.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
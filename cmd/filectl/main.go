@@ -0,0 +1,107 @@
+// Command filectl is a command-line client for the file service.
+//
+//	filectl [global flags] <command> [flags] [args]
+//
+// Remote paths are written with an "fs:" prefix wherever local and remote
+// paths can both appear, as in "filectl cp -r ./photos fs:backup/".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, c *client, args []string) error
+}
+
+var commands []command
+
+// The table is filled in init because the commands look themselves up in it.
+func init() {
+	commands = []command{
+		{"upload", "upload [-r] [-p N] <local>... <remote>", "upload local files or directories", runUpload},
+		{"download", "download [-r] [-p N] <remote>... <local>", "download remote files, globs or prefixes", runDownload},
+		{"ls", "ls [-l] [prefix|glob]", "list remote files", runLs},
+		{"stat", "stat <remote>...", "show metadata of remote files", runStat},
+		{"cp", "cp [-r] [-p N] <src>... <dst>", "copy between local paths and fs: paths", runCp},
+		{"sync", "sync [-dry-run] [-delete] [-exclude PATTERN]... <local-dir> fs:<prefix> | fs:<prefix> <local-dir>", "make one side a mirror of the other, transferring only differences", runSync},
+	}
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "filectl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("filectl", flag.ContinueOnError)
+	var opts connOptions
+	opts.register(fset)
+	fset.Usage = func() {
+		out := fset.Output()
+		fmt.Fprintf(out, "usage: filectl [global flags] <command> [flags] [args]\n\ncommands:\n")
+		for _, c := range commands {
+			fmt.Fprintf(out, "  %-10s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintf(out, "\nglobal flags:\n")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return flag.ErrHelp
+	}
+
+	name := fset.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		cl, err := dial(opts)
+		if err != nil {
+			return err
+		}
+		defer cl.Close()
+		if opts.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+			defer cancel()
+		}
+		return c.run(ctx, cl, fset.Args()[1:])
+	}
+	fset.Usage()
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// subcommand returns the flag set of the named command.
+func subcommand(name string) *flag.FlagSet {
+	fset := flag.NewFlagSet(name, flag.ContinueOnError)
+	fset.Usage = func() {
+		for _, c := range commands {
+			if c.name == name {
+				fmt.Fprintf(fset.Output(), "usage: filectl %s\n\n%s\n\nflags:\n", c.usage, c.summary)
+			}
+		}
+		fset.PrintDefaults()
+	}
+	return fset
+}
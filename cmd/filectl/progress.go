@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progress draws a single progress bar for all transfers of a command on
+// stderr. It stays silent when stderr is not a terminal.
+type progress struct {
+	out        io.Writer
+	enabled    bool
+	start      time.Time
+	totalBytes atomic.Int64
+	doneBytes  atomic.Int64
+	totalFiles atomic.Int64
+	doneFiles  atomic.Int64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProgress(quiet bool) *progress {
+	p := &progress{out: os.Stderr, start: time.Now(), stop: make(chan struct{})}
+	if fi, err := os.Stderr.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		p.enabled = !quiet
+	}
+	if p.enabled {
+		p.wg.Add(1)
+		go p.loop()
+	}
+	return p
+}
+
+func (p *progress) addFile(size int64) {
+	p.totalFiles.Add(1)
+	p.totalBytes.Add(size)
+}
+
+func (p *progress) fileDone() {
+	p.doneFiles.Add(1)
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.doneBytes.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Reader counts bytes read from r as transferred.
+func (p *progress) Reader(r io.Reader) io.Reader {
+	return io.TeeReader(r, p)
+}
+
+// Writer counts bytes written to w as transferred.
+func (p *progress) Writer(w io.Writer) io.Writer {
+	return io.MultiWriter(w, p)
+}
+
+// Printf prints a message above the bar.
+func (p *progress) Printf(format string, args ...any) {
+	if p.enabled {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+	fmt.Fprintf(p.out, format, args...)
+}
+
+func (p *progress) Finish() {
+	if !p.enabled {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.render()
+	fmt.Fprintln(p.out)
+}
+
+func (p *progress) loop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.render()
+		}
+	}
+}
+
+func (p *progress) render() {
+	const width = 30
+	done, total := p.doneBytes.Load(), p.totalBytes.Load()
+	frac := 1.0
+	if total > 0 {
+		frac = min(float64(done)/float64(total), 1)
+	}
+	filled := int(frac * width)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	if filled > 0 && filled < width {
+		bar = bar[:filled-1] + ">" + bar[filled:]
+	}
+	rate := float64(done) / max(time.Since(p.start).Seconds(), 0.001)
+	fmt.Fprintf(p.out, "\r\033[K[%s] %3.0f%%  %s/%s  %d/%d files  %s/s",
+		bar, frac*100, humanBytes(done), humanBytes(total),
+		p.doneFiles.Load(), p.totalFiles.Load(), humanBytes(int64(rate)))
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type syncFlags struct {
+	dryRun   bool
+	delete   bool
+	excludes []string
+	state    string
+	parallel int
+	quiet    bool
+}
+
+func (f *syncFlags) register(fset *flag.FlagSet) {
+	fset.BoolVar(&f.dryRun, "dry-run", false, "show what would be transferred and deleted without doing it")
+	fset.BoolVar(&f.delete, "delete", false, "delete destination files that are missing from the source (remote files go to the trash)")
+	fset.Func("exclude", "skip files and directories matching `PATTERN` (repeatable)", func(p string) error {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", p, err)
+		}
+		f.excludes = append(f.excludes, p)
+		return nil
+	})
+	fset.StringVar(&f.state, "state", "", "state file remembering synced files (default <local-dir>/"+defaultStateFile+")")
+	fset.IntVar(&f.parallel, "p", 4, "number of parallel transfers")
+	fset.BoolVar(&f.quiet, "q", false, "do not show progress")
+}
+
+// excluded reports whether rel, or a directory it lies in, matches one of
+// the patterns, either as a whole or by its last element.
+func (f *syncFlags) excluded(rel string) bool {
+	for _, p := range f.excludes {
+		for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if ok, _ := path.Match(p, dir); ok {
+				return true
+			}
+			if ok, _ := path.Match(p, path.Base(dir)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// localFile and remoteFile describe the two sides of a synced file; rel is
+// its slash-separated path below the local directory and the remote prefix.
+type localFile struct {
+	rel   string
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+type remoteFile struct {
+	rel     string
+	name    string
+	size    int64
+	etag    string
+	digest  string
+	updated time.Time
+}
+
+// deletion is a destination file missing from the source, as listed.
+type deletion struct {
+	target string
+	etag   string    // of a remote file
+	local  localFile // of a local file
+}
+
+var errChangedSinceListing = errors.New("changed since it was listed, skipped")
+
+func runSync(ctx context.Context, c *client, args []string) error {
+	fset := subcommand("sync")
+	var f syncFlags
+	f.register(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 2 {
+		fset.Usage()
+		return fmt.Errorf("sync needs a source and a destination")
+	}
+	src, dst := fset.Arg(0), fset.Arg(1)
+	if isRemote(src) == isRemote(dst) {
+		return fmt.Errorf("sync needs one local directory and one %s prefix", remotePrefix)
+	}
+
+	s := &syncer{c: c, flags: f, upload: !isRemote(src)}
+	if s.upload {
+		s.dir, s.prefix = src, trimRemote(dst)
+	} else {
+		s.dir, s.prefix = dst, trimRemote(src)
+	}
+	s.prefix = strings.Trim(s.prefix, "/")
+	s.statePath = f.state
+	if s.statePath == "" {
+		s.statePath = filepath.Join(s.dir, defaultStateFile)
+	}
+	return s.run(ctx)
+}
+
+// syncer makes the destination side a copy of the source side. Files are
+// compared by the state of the previous run when there is one; otherwise by
+// size and SHA-256 digest if the server stored one, and failing that by size
+// and modification time, the destination counting as current when it is no
+// older than the source.
+type syncer struct {
+	c         *client
+	flags     syncFlags
+	upload    bool // local to remote, otherwise remote to local
+	dir       string
+	prefix    string
+	statePath string
+
+	prev *syncState
+	mu   sync.Mutex
+	next *syncState
+}
+
+func (s *syncer) run(ctx context.Context) error {
+	local, err := s.listLocal()
+	if err != nil {
+		return err
+	}
+	remote, err := s.listRemote(ctx)
+	if err != nil {
+		return err
+	}
+	s.prev = loadState(s.statePath, s.prefix)
+	s.next = &syncState{Prefix: s.prefix, Files: make(map[string]stateFile)}
+
+	// Transfers and deletions are conditioned on the destination being as
+	// listed, so that a change made by someone else meanwhile is not lost.
+	var jobs []job
+	var deletes []deletion
+	current := 0
+	if s.upload {
+		for _, rel := range sortedKeys(local) {
+			l := local[rel]
+			if r, ok := remote[rel]; ok && s.inSync(l, r) {
+				s.next.record(l, r)
+				current++
+				continue
+			}
+			jobs = append(jobs, job{src: l.path, dst: joinRemote(s.prefix, rel), size: l.size, etag: remote[rel].etag})
+		}
+		for _, rel := range sortedKeys(remote) {
+			if _, ok := local[rel]; !ok {
+				deletes = append(deletes, deletion{target: remote[rel].name, etag: remote[rel].etag})
+			}
+		}
+	} else {
+		for _, rel := range sortedKeys(remote) {
+			r := remote[rel]
+			if l, ok := local[rel]; ok && s.inSync(l, r) {
+				s.next.record(l, r)
+				current++
+				continue
+			}
+			jobs = append(jobs, job{src: r.name, dst: filepath.Join(s.dir, filepath.FromSlash(rel)), size: r.size, etag: r.etag})
+		}
+		for _, rel := range sortedKeys(local) {
+			if _, ok := remote[rel]; !ok {
+				deletes = append(deletes, deletion{target: local[rel].path, local: local[rel]})
+			}
+		}
+	}
+	if !s.flags.delete {
+		deletes = nil
+	}
+
+	if s.flags.dryRun {
+		var total int64
+		for _, j := range jobs {
+			total += j.size
+			fmt.Printf("%s %s -> %s\n", s.verb(), j.src, j.dst)
+		}
+		for _, d := range deletes {
+			fmt.Printf("delete %s\n", d.target)
+		}
+		fmt.Printf("would transfer %d files (%s), delete %d; %d up to date\n", len(jobs), humanBytes(total), len(deletes), current)
+		return nil
+	}
+
+	if !s.upload {
+		if err := os.MkdirAll(s.dir, 0o755); err != nil {
+			return err
+		}
+	}
+	transferErr := s.transfer(ctx, jobs, local, remote)
+	saveErr := s.next.save(s.statePath)
+	if transferErr != nil {
+		// Deleting while the destination is incomplete would leave it
+		// further from the source, so only whole transfers go on to it.
+		return errors.Join(transferErr, saveErr)
+	}
+
+	deleted, deleteErr := s.deleteAll(ctx, deletes)
+	fmt.Printf("%d transferred, %d deleted, %d up to date\n", len(jobs), deleted, current)
+	return errors.Join(deleteErr, saveErr)
+}
+
+func (s *syncer) verb() string {
+	if s.upload {
+		return "upload"
+	}
+	return "download"
+}
+
+func (s *syncer) inSync(l localFile, r remoteFile) bool {
+	if _, ok := s.prev.Files[l.rel]; ok {
+		if s.prev.unchanged(l, r) {
+			return true
+		}
+		// A side changed since the last sync; only the content can show
+		// that it changed back.
+		return l.size == r.size && r.digest != "" && sameDigest(l.path, r.digest)
+	}
+	if l.size != r.size {
+		return false
+	}
+	if r.digest != "" {
+		return sameDigest(l.path, r.digest)
+	}
+	if s.upload {
+		return !r.updated.Before(l.mtime)
+	}
+	return !l.mtime.Before(r.updated)
+}
+
+func sameDigest(name, digest string) bool {
+	f, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return hex.EncodeToString(h.Sum(nil)) == digest
+}
+
+func (s *syncer) transfer(ctx context.Context, jobs []job, local map[string]localFile, remote map[string]remoteFile) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	p := newProgress(s.flags.quiet)
+	if s.upload {
+		return runJobs(ctx, jobs, s.flags.parallel, p, func(ctx context.Context, j job) error {
+			// A file missing at listing time must still be missing.
+			etag, err := uploadFile(ctx, s.c, j, j.etag == "", p)
+			if err != nil {
+				return remoteConflict(j.dst, err)
+			}
+			rel := s.remoteRel(j.dst)
+			s.mu.Lock()
+			s.next.record(local[rel], remoteFile{rel: rel, name: j.dst, etag: etag})
+			s.mu.Unlock()
+			return nil
+		})
+	}
+	return runJobs(ctx, jobs, s.flags.parallel, p, func(ctx context.Context, j job) error {
+		rel := s.remoteRel(j.src)
+		if l, ok := local[rel]; ok {
+			if err := checkUnchanged(l); err != nil {
+				return err
+			}
+		}
+		if err := downloadFile(ctx, s.c, j, false, p); err != nil {
+			return remoteConflict(j.src, err)
+		}
+		r := remote[rel]
+		// Giving the copy the remote modification time keeps the size and
+		// time comparison meaningful should the state file be lost.
+		if err := os.Chtimes(j.dst, r.updated, r.updated); err != nil {
+			return err
+		}
+		fi, err := os.Stat(j.dst)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.next.record(localFile{rel: rel, path: j.dst, size: fi.Size(), mtime: fi.ModTime()}, r)
+		s.mu.Unlock()
+		return nil
+	})
+}
+
+// remoteConflict tells a remote file that changed since it was listed from
+// other failures.
+func remoteConflict(name string, err error) error {
+	switch status.Code(err) {
+	case codes.FailedPrecondition, codes.AlreadyExists:
+		return fmt.Errorf("%s %w: %w", name, errChangedSinceListing, err)
+	}
+	return err
+}
+
+// checkUnchanged fails unless the local file is as it was listed. Local
+// files cannot be replaced or removed conditionally, so this only narrows
+// the window for a concurrent change to the time until the file is used.
+func checkUnchanged(l localFile) error {
+	fi, err := os.Stat(l.path)
+	if err != nil {
+		return err
+	}
+	if fi.Size() != l.size || !fi.ModTime().Equal(l.mtime) {
+		return fmt.Errorf("%s %w", l.path, errChangedSinceListing)
+	}
+	return nil
+}
+
+func (s *syncer) deleteAll(ctx context.Context, targets []deletion) (int, error) {
+	failed := 0
+	for _, t := range targets {
+		var err error
+		if s.upload {
+			_, err = s.c.DeleteFile(ctx, &pb.DeleteFileRequest{Filename: t.target, IfMatch: t.etag})
+			err = remoteConflict(t.target, err)
+		} else if err = checkUnchanged(t.local); err == nil {
+			err = os.Remove(t.target)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "filectl: delete %s: %v\n", t.target, err)
+		}
+	}
+	if failed > 0 {
+		return len(targets) - failed, fmt.Errorf("%d of %d deletions failed", failed, len(targets))
+	}
+	return len(targets), nil
+}
+
+func (s *syncer) listLocal() (map[string]localFile, error) {
+	files := make(map[string]localFile)
+	statePath, _ := filepath.Abs(s.statePath)
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == s.dir && errors.Is(err, fs.ErrNotExist) && !s.upload {
+				return fs.SkipAll
+			}
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if s.flags.excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if abs, _ := filepath.Abs(p); abs == statePath {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[rel] = localFile{rel: rel, path: p, size: info.Size(), mtime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (s *syncer) listRemote(ctx context.Context) (map[string]remoteFile, error) {
+	resp, err := s.c.ListFiles(ctx, &pb.ListFilesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+	files := make(map[string]remoteFile)
+	for _, m := range resp.GetFiles() {
+		rel := s.remoteRel(m.GetName())
+		if rel == "" || s.flags.excluded(rel) {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			fmt.Fprintf(os.Stderr, "filectl: skipping %s: not a valid local path\n", m.GetName())
+			continue
+		}
+		files[rel] = remoteFile{
+			rel:     rel,
+			name:    m.GetName(),
+			size:    m.GetSize(),
+			etag:    m.GetEtag(),
+			digest:  m.GetDigest(),
+			updated: m.GetUpdatedAt().AsTime(),
+		}
+	}
+	return files, nil
+}
+
+// remoteRel returns name relative to the prefix, or "" when name lies
+// outside it.
+func (s *syncer) remoteRel(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	rel, ok := strings.CutPrefix(name, s.prefix+"/")
+	if !ok {
+		return ""
+	}
+	return rel
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
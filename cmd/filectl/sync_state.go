@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultStateFile = ".filectl-sync.json"
+
+// syncState remembers, for every file the last sync left identical on both
+// sides, the local size and modification time and the remote ETag. A file
+// whose three values are unchanged is skipped without reading it.
+type syncState struct {
+	Prefix string               `json:"prefix"`
+	Files  map[string]stateFile `json:"files"`
+}
+
+type stateFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	ETag    string    `json:"etag"`
+}
+
+// loadState reads the state file. A missing or unreadable file, or one
+// written for another prefix, yields an empty state: the sync then compares
+// content and rebuilds it.
+func loadState(path, prefix string) *syncState {
+	s := &syncState{Prefix: prefix, Files: make(map[string]stateFile)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	var saved syncState
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Prefix != prefix || saved.Files == nil {
+		return s
+	}
+	return &saved
+}
+
+func (s *syncState) unchanged(l localFile, r remoteFile) bool {
+	e, ok := s.Files[l.rel]
+	return ok && e.Size == l.size && e.ModTime.Equal(l.mtime) && e.ETag == r.etag
+}
+
+func (s *syncState) record(l localFile, r remoteFile) {
+	s.Files[l.rel] = stateFile{Size: l.size, ModTime: l.mtime, ETag: r.etag}
+}
+
+// save replaces the state file atomically, so that an interrupted sync
+// leaves the previous state rather than a truncated one.
+func (s *syncState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/internal/fakeserver"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeLocal(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	tests := []struct {
+		name     string
+		upload   bool
+		local    map[string]string
+		remote   map[string]string // names below the prefix
+		onList   func(dir string, fake *fakeserver.Server)
+		wantErr  bool
+		wantLoc  map[string]string
+		wantRem  map[string]string
+		wantTrsh []string
+	}{
+		{
+			name:    "upload new and changed files",
+			upload:  true,
+			local:   map[string]string{"a": "new", "d/b": "changed"},
+			remote:  map[string]string{"d/b": "old", "c": "stale"},
+			wantRem: map[string]string{"a": "new", "d/b": "changed"},
+			// c is missing locally and -delete is set.
+			wantTrsh: []string{"p/c"},
+		},
+		{
+			name:   "upload keeps a remote overwrite made after listing",
+			upload: true,
+			local:  map[string]string{"a": "mine"},
+			remote: map[string]string{"a": "old"},
+			onList: func(_ string, fake *fakeserver.Server) {
+				fake.Put("p/a", []byte("theirs"))
+			},
+			wantErr: true,
+			wantRem: map[string]string{"a": "theirs"},
+		},
+		{
+			name:   "upload keeps a remote file created after listing",
+			upload: true,
+			local:  map[string]string{"a": "mine"},
+			onList: func(_ string, fake *fakeserver.Server) {
+				fake.Put("p/a", []byte("theirs"))
+			},
+			wantErr: true,
+			wantRem: map[string]string{"a": "theirs"},
+		},
+		{
+			name:   "delete keeps a remote file changed after listing",
+			upload: true,
+			local:  map[string]string{},
+			remote: map[string]string{"a": "old", "b": "gone"},
+			onList: func(_ string, fake *fakeserver.Server) {
+				fake.Put("p/a", []byte("theirs"))
+			},
+			wantErr:  true,
+			wantRem:  map[string]string{"a": "theirs"},
+			wantTrsh: []string{"p/b"},
+		},
+		{
+			name:    "download new and changed files",
+			local:   map[string]string{"b": "old", "c": "stale"},
+			remote:  map[string]string{"a": "new", "d/b": "nested", "b": "changed"},
+			wantLoc: map[string]string{"a": "new", "d/b": "nested", "b": "changed"},
+		},
+		{
+			name:   "download skips a remote file changed after listing",
+			remote: map[string]string{"a": "old"},
+			onList: func(_ string, fake *fakeserver.Server) {
+				fake.Put("p/a", []byte("theirs"))
+			},
+			wantErr: true,
+			wantLoc: map[string]string{},
+		},
+		{
+			name:   "download keeps a local file changed after listing",
+			local:  map[string]string{"a": "mine"},
+			remote: map[string]string{"a": "old"},
+			onList: func(dir string, _ *fakeserver.Server) {
+				_ = os.WriteFile(filepath.Join(dir, "a"), []byte("mine, edited"), 0o644)
+			},
+			wantErr: true,
+			wantLoc: map[string]string{"a": "mine, edited"},
+		},
+		{
+			name:   "delete keeps a local file changed after listing",
+			local:  map[string]string{"a": "mine", "b": "gone"},
+			remote: map[string]string{},
+			onList: func(dir string, _ *fakeserver.Server) {
+				_ = os.WriteFile(filepath.Join(dir, "a"), []byte("mine, edited"), 0o644)
+			},
+			wantErr: true,
+			wantLoc: map[string]string{"a": "mine, edited"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeserver.New()
+			conn := fakeserver.Dial(t, fake)
+			c := &client{FileServiceClient: pb.NewFileServiceClient(conn), conn: conn}
+			dir := t.TempDir()
+			writeLocal(t, dir, tt.local)
+			for name, data := range tt.remote {
+				fake.Put("p/"+name, []byte(data))
+			}
+			if tt.onList != nil {
+				fake.OnList = func() { tt.onList(dir, fake) }
+			}
+
+			args := []string{"-q", "-delete", "-state", filepath.Join(t.TempDir(), "state.json"), dir, "fs:p"}
+			if !tt.upload {
+				args[len(args)-2], args[len(args)-1] = "fs:p", dir
+			}
+			err := runSync(context.Background(), c, args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+
+			if tt.wantRem != nil {
+				resp, err := fake.ListFiles(context.Background(), &pb.ListFilesRequest{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := make(map[string]string)
+				for _, m := range resp.GetFiles() {
+					data, _ := fake.Get(m.GetName())
+					got[m.GetName()[len("p/"):]] = string(data)
+				}
+				if !maps.Equal(got, tt.wantRem) {
+					t.Errorf("remote = %v, want %v", got, tt.wantRem)
+				}
+			}
+			if got := fake.Trashed(); !slices.Equal(got, tt.wantTrsh) {
+				t.Errorf("trashed = %v, want %v", got, tt.wantTrsh)
+			}
+			if tt.wantLoc != nil {
+				got := make(map[string]string)
+				err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+					if err != nil || d.IsDir() {
+						return err
+					}
+					data, err := os.ReadFile(p)
+					rel, _ := filepath.Rel(dir, p)
+					got[filepath.ToSlash(rel)] = string(data)
+					return err
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !maps.Equal(got, tt.wantLoc) {
+					t.Errorf("local = %v, want %v", got, tt.wantLoc)
+				}
+			}
+		})
+	}
+}
+
+// The state records the ETag of what was uploaded, not whatever the file
+// holds by the time the sync ends.
+func TestSyncRecordsUploadedETag(t *testing.T) {
+	fake := fakeserver.New()
+	conn := fakeserver.Dial(t, fake)
+	c := &client{FileServiceClient: pb.NewFileServiceClient(conn), conn: conn}
+	dir := t.TempDir()
+	writeLocal(t, dir, map[string]string{"a": "mine"})
+	state := filepath.Join(t.TempDir(), "state.json")
+
+	if err := runSync(context.Background(), c, []string{"-q", "-state", state, dir, "fs:p"}); err != nil {
+		t.Fatal(err)
+	}
+	s := loadState(state, "p")
+	if got, want := s.Files["a"].ETag, fakeserver.ETag([]byte("mine")); got != want {
+		t.Errorf("recorded etag = %q, want %q", got, want)
+	}
+
+	// Another writer replaces the file with one of the same size; the next
+	// sync must notice and upload again rather than trust the state.
+	fake.Put("p/a", []byte("them"))
+	if err := runSync(context.Background(), c, []string{"-q", "-state", state, dir, "fs:p"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fake.Get("p/a"); string(data) != "mine" {
+		t.Errorf("remote = %q, want %q", data, "mine")
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"golang.org/x/sync/errgroup"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	remotePrefix    = "fs:"
+	uploadChunkSize = 256 << 10 // 256 KiB
+)
+
+type transferFlags struct {
+	recursive bool
+	parallel  int
+	quiet     bool
+	noClobber bool
+}
+
+func (f *transferFlags) register(fset *flag.FlagSet) {
+	fset.BoolVar(&f.recursive, "r", false, "transfer directories and prefixes recursively")
+	fset.IntVar(&f.parallel, "p", 4, "number of parallel transfers")
+	fset.BoolVar(&f.quiet, "q", false, "do not show progress")
+	fset.BoolVar(&f.noClobber, "n", false, "do not overwrite existing files")
+}
+
+// job is one file to transfer. Remote names never carry the fs: prefix.
+type job struct {
+	src  string
+	dst  string
+	size int64
+	etag string // if set, the transfer fails unless the remote file has this ETag
+}
+
+// runJobs runs do for every job, parallel at a time. A failed job does not
+// stop the others; failures are reported as they happen and summarised in
+// the returned error.
+func runJobs(ctx context.Context, jobs []job, parallel int, p *progress, do func(context.Context, job) error) error {
+	for _, j := range jobs {
+		p.addFile(j.size)
+	}
+	var g errgroup.Group
+	g.SetLimit(max(parallel, 1))
+	errs := make([]error, len(jobs))
+	for i, j := range jobs {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return nil
+			}
+			if err := do(ctx, j); err != nil {
+				errs[i] = err
+				p.Printf("%s -> %s: %v\n", j.src, j.dst, err)
+				return nil
+			}
+			p.fileDone()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	p.Finish()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transfers failed", failed, len(jobs))
+	}
+	return nil
+}
+
+func hasMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+func isRemote(s string) bool {
+	return strings.HasPrefix(s, remotePrefix)
+}
+
+func trimRemote(s string) string {
+	return strings.TrimPrefix(s, remotePrefix)
+}
+
+func joinRemote(prefix, rel string) string {
+	if prefix == "" {
+		return rel
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + rel
+}
+
+// expandLocal resolves glob patterns among local arguments; the shell has
+// usually done so already, but quoted patterns reach us unexpanded.
+func expandLocal(args []string) ([]string, error) {
+	var out []string
+	for _, a := range args {
+		if !hasMeta(a) {
+			out = append(out, a)
+			continue
+		}
+		matches, err := filepath.Glob(a)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", a, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no matches", a)
+		}
+		out = append(out, matches...)
+	}
+	return out, nil
+}
+
+func planUpload(srcs []string, dst string, recursive bool) ([]job, error) {
+	dstIsDir := dst == "" || strings.HasSuffix(dst, "/") || len(srcs) > 1
+	var jobs []job
+	for _, src := range srcs {
+		fi, err := os.Stat(src)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			name := dst
+			if dstIsDir {
+				name = joinRemote(dst, filepath.Base(src))
+			}
+			jobs = append(jobs, job{src: src, dst: name, size: fi.Size()})
+			continue
+		}
+		if !recursive {
+			return nil, fmt.Errorf("%s is a directory (use -r)", src)
+		}
+		base := filepath.Dir(filepath.Clean(src))
+		err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(base, p)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job{src: p, dst: joinRemote(dst, filepath.ToSlash(rel)), size: info.Size()})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// planRemote resolves remote names, globs and, with recursive, prefixes to
+// the files they denote. The dst of each job is the path the file keeps
+// below the destination; callers turn it into the final one.
+func planRemote(ctx context.Context, c *client, srcs []string, recursive bool) (files []job, multi bool, err error) {
+	resp, err := c.ListFiles(ctx, &pb.ListFilesRequest{})
+	if err != nil {
+		return nil, false, fmt.Errorf("list files: %w", err)
+	}
+	sizes := make(map[string]int64, len(resp.GetFiles()))
+	names := make([]string, 0, len(resp.GetFiles()))
+	for _, f := range resp.GetFiles() {
+		sizes[f.GetName()] = f.GetSize()
+		names = append(names, f.GetName())
+	}
+	sort.Strings(names)
+
+	multi = len(srcs) > 1
+	for _, src := range srcs {
+		src = trimRemote(src)
+		before := len(files)
+		switch {
+		case hasMeta(src):
+			multi = true
+			for _, name := range names {
+				if ok, _ := path.Match(src, name); ok {
+					files = append(files, job{src: name, dst: path.Base(name), size: sizes[name]})
+				}
+			}
+		case recursive:
+			multi = true
+			prefix := strings.TrimSuffix(src, "/")
+			parent := path.Dir(prefix)
+			for _, name := range names {
+				if prefix != "" && name != prefix && !strings.HasPrefix(name, prefix+"/") {
+					continue
+				}
+				rel := name
+				if parent != "." && parent != "/" {
+					rel = strings.TrimPrefix(name, parent+"/")
+				}
+				files = append(files, job{src: name, dst: rel, size: sizes[name]})
+			}
+		default:
+			size, ok := sizes[src]
+			if !ok {
+				return nil, false, fmt.Errorf("%s: file not found", src)
+			}
+			files = append(files, job{src: src, dst: path.Base(src), size: size})
+		}
+		if len(files) == before {
+			return nil, false, fmt.Errorf("%s: no matches", src)
+		}
+	}
+	return files, multi, nil
+}
+
+func planDownload(ctx context.Context, c *client, srcs []string, dst string, recursive bool) ([]job, error) {
+	files, multi, err := planRemote(ctx, c, srcs, recursive)
+	if err != nil {
+		return nil, err
+	}
+	dstIsDir := multi || strings.HasSuffix(dst, string(os.PathSeparator)) || strings.HasSuffix(dst, "/")
+	if fi, err := os.Stat(dst); err == nil && fi.IsDir() {
+		dstIsDir = true
+	}
+	for i, f := range files {
+		if !dstIsDir {
+			files[i].dst = dst
+			continue
+		}
+		rel := filepath.FromSlash(f.dst)
+		if !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("%s: refusing to write outside %s", f.src, dst)
+		}
+		files[i].dst = filepath.Join(dst, rel)
+	}
+	return files, nil
+}
+
+func planCopy(ctx context.Context, c *client, srcs []string, dst string, recursive bool) ([]job, error) {
+	files, multi, err := planRemote(ctx, c, srcs, recursive)
+	if err != nil {
+		return nil, err
+	}
+	dstIsDir := multi || dst == "" || strings.HasSuffix(dst, "/")
+	for i, f := range files {
+		if dstIsDir {
+			files[i].dst = joinRemote(dst, f.dst)
+		} else {
+			files[i].dst = dst
+		}
+	}
+	return files, nil
+}
+
+// uploadFile returns the ETag of the uploaded file.
+func uploadFile(ctx context.Context, c *client, j job, noClobber bool, p *progress) (string, error) {
+	f, err := os.Open(j.src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	stream, err := c.UploadFile(ctx)
+	if err != nil {
+		return "", err
+	}
+	first := &pb.UploadFileRequest{Filename: j.dst, IfMatch: j.etag}
+	if noClobber {
+		first.IfNoneMatch = "*"
+	}
+	if err := stream.Send(first); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	r := p.Reader(f)
+	buf := make([]byte, uploadChunkSize)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.UploadFileRequest{Chunk: buf[:n]}); err != nil {
+				// io.EOF means the server gave up; CloseAndRecv tells why.
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return "", err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return "", rerr
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", err
+	}
+	return resp.GetEtag(), nil
+}
+
+// downloadFile writes into a temporary file next to the destination, so an
+// interrupted download never leaves a truncated file behind.
+func downloadFile(ctx context.Context, c *client, j job, noClobber bool, p *progress) error {
+	if noClobber {
+		if _, err := os.Lstat(j.dst); err == nil {
+			return fmt.Errorf("%s already exists", j.dst)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(j.dst), 0o755); err != nil {
+		return err
+	}
+	stream, err := c.DownloadFile(ctx, &pb.DownloadFileRequest{Filename: j.src, IfMatch: j.etag})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.dst), "."+filepath.Base(j.dst)+".part-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	w := p.Writer(tmp)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(resp.GetChunk()); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), j.dst)
+}
+
+func copyRemote(ctx context.Context, c *client, j job, noClobber bool, p *progress) error {
+	_, err := c.CopyFile(ctx, &pb.CopyFileRequest{Source: j.src, Destination: j.dst, Overwrite: !noClobber})
+	if err == nil {
+		p.doneBytes.Add(j.size)
+	}
+	return err
+}
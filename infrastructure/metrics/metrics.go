@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"sync"
+)
+
+var mu sync.Mutex
+
+// Counter returns the process-wide expvar counter with the given name,
+// creating it on first use. Counters are served by Handler.
+func Counter(name string) *expvar.Int {
+	mu.Lock()
+	defer mu.Unlock()
+	if v, ok := expvar.Get(name).(*expvar.Int); ok {
+		return v
+	}
+	return expvar.NewInt(name)
+}
+
+// Handler serves all expvar variables, the counters among them, as JSON
+// under /debug/vars.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	return mux
+}
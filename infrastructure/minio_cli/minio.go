@@ -50,5 +50,13 @@ func NewClient(ctx context.Context, cfg *config.Config) (*minio.Client, error) {
 		l.Infow("bucket exists", "bucket", cfg.MinioConfig.BucketName)
 	}
 
+	if cfg.MinioConfig.Versioning {
+		l.Infow("enabling bucket versioning", "bucket", cfg.MinioConfig.BucketName)
+		if err := client.EnableVersioning(ctx, cfg.MinioConfig.BucketName); err != nil {
+			l.Errorw("failed to enable bucket versioning", "bucket", cfg.MinioConfig.BucketName, "error", err)
+			return nil, fmt.Errorf("failed to enable versioning on bucket %s: %w", cfg.MinioConfig.BucketName, err)
+		}
+	}
+
 	return client, nil
 }
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/infrastructure/metrics"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"time"
+)
+
+var (
+	notificationEvents   = metrics.Counter("events_minio_notifications_total")
+	notificationFailures = metrics.Counter("events_minio_listen_failures_total")
+)
+
+// runEventSource feeds MinIO bucket notifications into bus. Whenever the
+// notification stream is unavailable the bus falls back to the events the
+// service emits itself, and listening is retried after retry.
+func runEventSource(ctx context.Context, stg storage.FileStorage, bus *events.Bus, retry time.Duration) {
+	l := logger.FromContext(ctx)
+	if retry <= 0 {
+		retry = 30 * time.Second
+	}
+	emit := func(e entity.FileEvent) {
+		notificationEvents.Add(1)
+		bus.Publish(events.SourceMinio, e)
+	}
+	for {
+		bus.SetSource(events.SourceMinio)
+		err := stg.ListenChanges(ctx, emit)
+		if ctx.Err() != nil {
+			l.Info("event source stopped")
+			return
+		}
+		notificationFailures.Add(1)
+		bus.SetSource(events.SourceLocal)
+		l.Warnw("bucket notifications unavailable, emitting events in process", "error", err, "retry_in", retry)
+
+		select {
+		case <-ctx.Done():
+			l.Info("event source stopped")
+			return
+		case <-time.After(retry):
+		}
+	}
+}
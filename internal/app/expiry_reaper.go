@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/infrastructure/metrics"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"time"
+)
+
+var (
+	reaperRuns     = metrics.Counter("expiry_reaper_runs_total")
+	reaperFailures = metrics.Counter("expiry_reaper_failures_total")
+	reapedFiles    = metrics.Counter("expiry_reaped_files_total")
+)
+
+func runExpiryReaper(ctx context.Context, svc service.FileService, interval time.Duration, batchSize int) {
+	l := logger.FromContext(ctx)
+	if interval <= 0 {
+		l.Info("expiry reaper disabled")
+		return
+	}
+	l.Infow("expiry reaper started", "interval", interval, "batch_size", batchSize)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			l.Info("expiry reaper stopped")
+			return
+		case <-ticker.C:
+			reaperRuns.Add(1)
+			reaped, err := svc.ReapExpired(ctx, batchSize)
+			reapedFiles.Add(int64(len(reaped)))
+			if len(reaped) > 0 {
+				l.Infow("expired files reaped", "count", len(reaped), "files", reaped)
+			}
+			if err != nil {
+				reaperFailures.Add(1)
+				l.Errorw("expiry reap failed", "error", err)
+			}
+		}
+	}
+}
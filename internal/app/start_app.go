@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/infrastructure/metrics"
 	"github.com/PianyCoder/test_file_service/infrastructure/minio_cli"
 	"github.com/PianyCoder/test_file_service/internal/config"
 	"github.com/PianyCoder/test_file_service/internal/controller"
+	"github.com/PianyCoder/test_file_service/internal/encryption"
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/gateway"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
 	"github.com/PianyCoder/test_file_service/internal/server"
 	"github.com/PianyCoder/test_file_service/internal/service"
 	"github.com/PianyCoder/test_file_service/internal/storage"
+	"github.com/PianyCoder/test_file_service/internal/webhooks"
+	"golang.org/x/sync/errgroup"
 )
 
 func Start(ctx context.Context) error {
@@ -38,12 +45,122 @@ func Start(ctx context.Context) error {
 	}
 	l.Info("minio client initialized")
 
-	stg := storage.NewMinioStorage(minioCli, cfg.MinioConfig.BucketName)
+	var stgOpts []storage.Option
+	if cfg.EncryptionConfig.Enabled {
+		keys, err := encryption.NewLocalKeyProvider(cfg.EncryptionConfig.KeyFile)
+		if err != nil {
+			l.Errorw("failed to initialize key provider", "error", err)
+			return fmt.Errorf("failed to initialize key provider: %w", err)
+		}
+		stgOpts = append(stgOpts, storage.WithEncryption(keys, cfg.EncryptionConfig.ChunkSize))
+		l.Infow("client-side encryption enabled", "chunk_size", cfg.EncryptionConfig.ChunkSize)
+	}
+
+	if cfg.MinioConfig.Versioning {
+		stgOpts = append(stgOpts, storage.WithVersioning())
+	}
+
+	if cfg.MinioConfig.Dedup {
+		if cfg.MinioConfig.Versioning {
+			l.Error("deduplication cannot be combined with bucket versioning")
+			return fmt.Errorf("deduplication cannot be combined with bucket versioning")
+		}
+		stgOpts = append(stgOpts, storage.WithDeduplication())
+		l.Info("content deduplication enabled")
+	}
+
+	if cfgM := cfg.MinioConfig; cfgM.Multipart {
+		stgOpts = append(stgOpts, storage.WithParallelMultipart(cfgM.MultipartPartSize, cfgM.MultipartParallelism))
+		l.Infow("parallel multipart uploads enabled", "part_size", cfgM.MultipartPartSize, "parallelism", cfgM.MultipartParallelism)
+	}
+
+	if cfgM := cfg.MinioConfig; cfgM.RangedReads {
+		stgOpts = append(stgOpts, storage.WithParallelRangedReads(cfgM.RangeWindowSize, cfgM.RangeParallelism))
+		l.Infow("parallel ranged reads enabled", "window_size", cfgM.RangeWindowSize, "parallelism", cfgM.RangeParallelism)
+	}
+
+	stg := storage.NewMinioStorage(minioCli, cfg.MinioConfig.BucketName, stgOpts...)
 	l.Infow("storage initialized", "bucket", cfg.MinioConfig.BucketName)
 
+	if cfgC := cfg.CacheConfig; cfgC.Dir != "" {
+		stg, err = storage.NewCachedStorage(stg, storage.CacheOptions{
+			Dir:                 cfgC.Dir,
+			MaxBytes:            cfgC.MaxBytes,
+			MaxObjectSize:       cfgC.MaxObjectSize,
+			MemoryMaxBytes:      cfgC.MemoryMaxBytes,
+			MemoryMaxObjectSize: cfgC.MemoryMaxObjectSize,
+		})
+		if err != nil {
+			l.Errorw("failed to initialize file cache", "error", err)
+			return fmt.Errorf("failed to initialize file cache: %w", err)
+		}
+		l.Infow("file cache enabled", "dir", cfgC.Dir, "max_bytes", cfgC.MaxBytes, "memory_max_bytes", cfgC.MemoryMaxBytes)
+	}
+
 	cfgS := cfg.ServiceConfig
-	svc := service.NewFileService(stg, cfgS.UploadLimit, cfgS.DownloadLimit, cfgS.ListLimit, cfgS.ChunkSize)
-	l.Infow("service initialized", "chunk_size", cfgS.ChunkSize)
+	cfgI := cfg.ImageConfig
+	imageLimits := imaging.Limits{
+		MaxSourceBytes:  cfgI.MaxSourceBytes,
+		MaxSourcePixels: cfgI.MaxSourcePixels,
+		MaxDimension:    cfgI.MaxDimension,
+		JPEGQuality:     cfgI.JPEGQuality,
+	}
+	cfgE := cfg.EventsConfig
+	eventSource := events.SourceLocal
+	if cfgE.MinioNotifications {
+		eventSource = events.SourceMinio
+	}
+	bus := events.NewBus(cfgE.BufferSize, eventSource)
+
+	svcOpts := []service.Option{
+		service.WithImageLimits(imageLimits),
+		service.WithExtractLimits(cfgS.ExtractMaxEntrySize, cfgS.ExtractMaxEntries, cfgS.ExtractMaxZipSize),
+		service.WithEvents(bus),
+		service.WithMaxChunkSize(cfgS.MaxChunkSize),
+	}
+	if cfgC := cfg.CompressionConfig; cfgC.Auto {
+		if !storage.IsSupportedCompression(cfgC.Algorithm) {
+			l.Errorw("unsupported compression algorithm", "algorithm", cfgC.Algorithm)
+			return fmt.Errorf("unsupported compression algorithm %q", cfgC.Algorithm)
+		}
+		svcOpts = append(svcOpts, service.WithAutoCompression(cfgC.Algorithm))
+		l.Infow("automatic compression enabled", "algorithm", cfgC.Algorithm)
+	}
+	svc := service.NewFileService(stg, cfgS.UploadLimit, cfgS.DownloadLimit, cfgS.ListLimit, cfgS.ChunkSize, svcOpts...)
+	l.Infow("service initialized", "chunk_size", cfgS.ChunkSize, "max_chunk_size", cfgS.MaxChunkSize)
+
+	go runTrashPurger(ctx, svc, cfgS.TrashPurgeInterval, cfgS.TrashRetention)
+	go runExpiryReaper(ctx, svc, cfgS.ExpiryInterval, cfgS.ExpiryBatchSize)
+	if cfgE.MinioNotifications {
+		go runEventSource(ctx, stg, bus, cfgE.RetryInterval)
+	}
+
+	if cfgW := cfg.WebhooksConfig; cfgW.File != "" {
+		subs, err := webhooks.LoadSubscriptions(cfgW.File)
+		if err != nil {
+			l.Errorw("failed to load webhook subscriptions", "error", err)
+			return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+		}
+		deadLetter, err := webhooks.OpenDeadLetterLog(cfgW.DeadLetterFile)
+		if err != nil {
+			l.Errorw("failed to open webhook dead letter log", "error", err)
+			return fmt.Errorf("failed to open webhook dead letter log: %w", err)
+		}
+		defer func() { _ = deadLetter.Close() }()
+
+		dispatcher := webhooks.NewDispatcher(subs, deadLetter, webhooks.Options{
+			QueueSize:      cfgW.QueueSize,
+			MaxAttempts:    cfgW.MaxAttempts,
+			InitialBackoff: cfgW.InitialBackoff,
+			MaxBackoff:     cfgW.MaxBackoff,
+			Timeout:        cfgW.Timeout,
+		})
+		if cfgE.MinioNotifications && subscribesUpdates(subs) {
+			l.Warnw("bucket notifications report overwrites and attribute changes as created, webhooks for updated events never fire")
+		}
+		go runWebhookDispatcher(ctx, bus, dispatcher)
+		l.Infow("webhooks enabled", "subscriptions", len(subs), "dead_letter_file", cfgW.DeadLetterFile)
+	}
 
 	ctlr := controller.NewFileServiceHandler(svc)
 
@@ -54,9 +171,75 @@ func Start(ctx context.Context) error {
 	}
 	l.Infow("gRPC server created", "addr", cfg.ServerConfig.Addr)
 
-	if err := grpcServer.StartServer(ctx); err != nil {
-		l.Errorw("grpc server stopped with error", "error", err)
-		return fmt.Errorf("grpc server stopped: %w", err)
+	var httpServer *server.HTTPServer
+	if addr := cfg.ServerConfig.HTTPAddr; addr != "" {
+		httpServer, err = server.NewHTTPServer(addr, gateway.NewHandler(svc), zapLog)
+		if err != nil {
+			l.Errorw("failed to create HTTP server", "error", err)
+			return fmt.Errorf("failed to create http server: %w", err)
+		}
+		l.Infow("HTTP server created", "addr", addr)
+	}
+
+	var webServer *server.HTTPServer
+	if cfgSrv := cfg.ServerConfig; cfgSrv.WebAddr != "" {
+		cors := server.CORSConfig{AllowedOrigins: cfgSrv.CORSAllowedOrigins, MaxAge: cfgSrv.CORSMaxAge}
+		webServer, err = server.NewHTTPServer(cfgSrv.WebAddr, grpcServer.WebHandler(cors), zapLog)
+		if err != nil {
+			l.Errorw("failed to create gRPC-Web server", "error", err)
+			return fmt.Errorf("failed to create grpc-web server: %w", err)
+		}
+		l.Infow("gRPC-Web server created", "addr", cfgSrv.WebAddr, "cors_origins", cfgSrv.CORSAllowedOrigins)
+	}
+
+	var metricsServer *server.HTTPServer
+	if addr := cfg.ServerConfig.MetricsAddr; addr != "" {
+		metricsServer, err = server.NewHTTPServer(addr, metrics.Handler(), zapLog)
+		if err != nil {
+			l.Errorw("failed to create metrics server", "error", err)
+			return fmt.Errorf("failed to create metrics server: %w", err)
+		}
+		l.Infow("metrics server created", "addr", addr)
+	}
+
+	// Any server failing stops the others.
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		if err := grpcServer.StartServer(gctx); err != nil {
+			l.Errorw("grpc server stopped with error", "error", err)
+			return fmt.Errorf("grpc server stopped: %w", err)
+		}
+		return nil
+	})
+	if httpServer != nil {
+		g.Go(func() error {
+			if err := httpServer.StartServer(gctx); err != nil {
+				l.Errorw("http server stopped with error", "error", err)
+				return fmt.Errorf("http server stopped: %w", err)
+			}
+			return nil
+		})
+	}
+	if webServer != nil {
+		g.Go(func() error {
+			if err := webServer.StartServer(gctx); err != nil {
+				l.Errorw("grpc-web server stopped with error", "error", err)
+				return fmt.Errorf("grpc-web server stopped: %w", err)
+			}
+			return nil
+		})
+	}
+	if metricsServer != nil {
+		g.Go(func() error {
+			if err := metricsServer.StartServer(gctx); err != nil {
+				l.Errorw("metrics server stopped with error", "error", err)
+				return fmt.Errorf("metrics server stopped: %w", err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
 	l.Info("Server stopped gracefully")
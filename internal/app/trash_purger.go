@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"time"
+)
+
+func runTrashPurger(ctx context.Context, svc service.FileService, interval, retention time.Duration) {
+	l := logger.FromContext(ctx)
+	if interval <= 0 {
+		l.Info("trash purger disabled")
+		return
+	}
+	l.Infow("trash purger started", "interval", interval, "retention", retention)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			l.Info("trash purger stopped")
+			return
+		case <-ticker.C:
+			n, err := svc.PurgeTrash(ctx, retention)
+			if err != nil {
+				l.Errorw("trash purge failed", "error", err, "purged", n)
+				continue
+			}
+			if n > 0 {
+				l.Infow("trash purge finished", "purged", n)
+			}
+		}
+	}
+}
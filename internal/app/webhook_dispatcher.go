@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/webhooks"
+	"slices"
+	"time"
+)
+
+// runWebhookDispatcher feeds file events from bus to the webhook dispatcher.
+// If the subscription falls behind it resumes from the last event it saw;
+// events that dropped out of the bus buffer meanwhile are lost.
+func runWebhookDispatcher(ctx context.Context, bus *events.Bus, d *webhooks.Dispatcher) {
+	l := logger.FromContext(ctx)
+	l.Info("webhook dispatcher started")
+	go d.Run(ctx)
+
+	cursor := ""
+	for {
+		sub, err := bus.Subscribe(cursor, "")
+		if errors.Is(err, events.ErrCursorExpired) {
+			l.Warnw("webhook events lost, resuming from new events", "cursor", cursor)
+			cursor = ""
+			continue
+		}
+		if err != nil {
+			l.Errorw("failed to subscribe webhook dispatcher", "error", err)
+			return
+		}
+
+	consume:
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				l.Info("webhook dispatcher stopped")
+				return
+			case e, ok := <-sub.Events():
+				if !ok {
+					l.Warnw("webhook event subscription ended, resubscribing", "error", sub.Err(), "cursor", cursor)
+					break consume
+				}
+				cursor = e.Cursor
+				d.Enqueue(ctx, e)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			l.Info("webhook dispatcher stopped")
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// subscribesUpdates reports whether any of subs asks for updated events.
+func subscribesUpdates(subs []webhooks.Subscription) bool {
+	return slices.ContainsFunc(subs, func(s webhooks.Subscription) bool {
+		return len(s.Events) == 0 || slices.Contains(s.Events, entity.EventUpdated)
+	})
+}
@@ -0,0 +1,32 @@
+// Package bufpool shares byte buffers between transfers. Buffers are pooled
+// by power-of-two capacity, so that transfers with different chunk sizes
+// still reuse each other's buffers.
+package bufpool
+
+import (
+	"math/bits"
+	"sync"
+)
+
+var classes [bits.UintSize]sync.Pool
+
+// Get returns a buffer of length size. Its content is undefined.
+func Get(size int) []byte {
+	class := bits.Len(uint(max(size, 1) - 1))
+	if b, ok := classes[class].Get().(*[]byte); ok {
+		return (*b)[:size]
+	}
+	return make([]byte, size, 1<<class)
+}
+
+// Put returns b to the pool. b must not be used afterwards. Buffers not
+// obtained from Get are accepted if their capacity is a power of two and
+// dropped otherwise.
+func Put(b []byte) {
+	c := cap(b)
+	if c == 0 || c&(c-1) != 0 {
+		return
+	}
+	b = b[:0]
+	classes[bits.Len(uint(c-1))].Put(&b)
+}
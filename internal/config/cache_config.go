@@ -0,0 +1,11 @@
+package config
+
+// CacheConfig configures the local read-through cache of files. An empty Dir
+// disables it.
+type CacheConfig struct {
+	Dir                 string `env:"CACHE_DIR" envDefault:""`
+	MaxBytes            int64  `env:"CACHE_MAX_BYTES" envDefault:"1073741824"`         // 1 GiB
+	MaxObjectSize       int64  `env:"CACHE_MAX_OBJECT_SIZE" envDefault:"67108864"`     // 64 MiB
+	MemoryMaxBytes      int64  `env:"CACHE_MEMORY_MAX_BYTES" envDefault:"67108864"`    // 64 MiB
+	MemoryMaxObjectSize int64  `env:"CACHE_MEMORY_MAX_OBJECT_SIZE" envDefault:"65536"` // 64 KiB
+}
@@ -0,0 +1,6 @@
+package config
+
+type CompressionConfig struct {
+	Auto      bool   `env:"COMPRESSION_AUTO" envDefault:"false"`
+	Algorithm string `env:"COMPRESSION_ALGORITHM" envDefault:"zstd"`
+}
@@ -7,9 +7,15 @@ import (
 )
 
 type Config struct {
-	ServerConfig  ServerConfig
-	ServiceConfig ServiceConfig
-	MinioConfig   MinioConfig
+	ServerConfig      ServerConfig
+	ServiceConfig     ServiceConfig
+	MinioConfig       MinioConfig
+	EncryptionConfig  EncryptionConfig
+	ImageConfig       ImageConfig
+	CompressionConfig CompressionConfig
+	EventsConfig      EventsConfig
+	WebhooksConfig    WebhooksConfig
+	CacheConfig       CacheConfig
 }
 
 func Load() (*Config, error) {
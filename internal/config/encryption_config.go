@@ -0,0 +1,7 @@
+package config
+
+type EncryptionConfig struct {
+	Enabled   bool   `env:"ENCRYPTION_ENABLED" envDefault:"false"`
+	KeyFile   string `env:"ENCRYPTION_KEY_FILE" envDefault:""`
+	ChunkSize int    `env:"ENCRYPTION_CHUNK_SIZE" envDefault:"65536"` // 64 KiB
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+// EventsConfig configures the file event feed. Bucket notifications also see
+// writes that bypass the service, but report overwrites and attribute changes
+// as creations, so events come from the service itself unless
+// MinioNotifications is set.
+type EventsConfig struct {
+	BufferSize         int           `env:"EVENTS_BUFFER_SIZE" envDefault:"10000"`
+	MinioNotifications bool          `env:"EVENTS_MINIO_NOTIFICATIONS" envDefault:"false"`
+	RetryInterval      time.Duration `env:"EVENTS_RETRY_INTERVAL" envDefault:"30s"`
+}
@@ -0,0 +1,8 @@
+package config
+
+type ImageConfig struct {
+	MaxSourceBytes  int64 `env:"IMAGE_MAX_SOURCE_BYTES" envDefault:"52428800"` // 50 MiB
+	MaxSourcePixels int64 `env:"IMAGE_MAX_SOURCE_PIXELS" envDefault:"50000000"`
+	MaxDimension    int   `env:"IMAGE_MAX_DIMENSION" envDefault:"4096"`
+	JPEGQuality     int   `env:"IMAGE_JPEG_QUALITY" envDefault:"85"`
+}
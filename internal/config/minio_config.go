@@ -8,4 +8,19 @@ type MinioConfig struct {
 	UseSSL     bool   `env:"MINIO_USE_SSL" envDefault:"false"`
 	BucketName string `env:"MINIO_BUCKET_NAME" envDefault:"images-bucket"`
 	Location   string `env:"MINIO_LOCATION" envDefault:"us-east-1"`
+	Versioning bool   `env:"MINIO_VERSIONING" envDefault:"false"`
+	Dedup      bool   `env:"MINIO_DEDUP" envDefault:"false"`
+
+	// Multipart uploads parts of uploads of unknown size in parallel instead
+	// of spooling them to disk; each upload holds up to parallelism+1 parts
+	// in memory.
+	Multipart            bool `env:"MINIO_MULTIPART" envDefault:"false"`
+	MultipartPartSize    int  `env:"MINIO_MULTIPART_PART_SIZE" envDefault:"8388608"` // 8 MiB, at least 5 MiB
+	MultipartParallelism int  `env:"MINIO_MULTIPART_PARALLELISM" envDefault:"4"`
+
+	// RangedReads fetches objects larger than one window as parallel ranges;
+	// each download holds up to parallelism windows in memory.
+	RangedReads      bool `env:"MINIO_RANGED_READS" envDefault:"false"`
+	RangeWindowSize  int  `env:"MINIO_RANGE_WINDOW_SIZE" envDefault:"8388608"` // 8 MiB, at least 1 MiB
+	RangeParallelism int  `env:"MINIO_RANGE_PARALLELISM" envDefault:"4"`
 }
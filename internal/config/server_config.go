@@ -1,5 +1,16 @@
 package config
 
+import (
+	"time"
+)
+
 type ServerConfig struct {
-	Addr string `env:"SERVER_ADDR" envDefault:"0.0.0.0:8000"`
+	Addr     string `env:"SERVER_ADDR" envDefault:"0.0.0.0:8000"`
+	HTTPAddr string `env:"SERVER_HTTP_ADDR" envDefault:"0.0.0.0:8080"` // empty disables the HTTP API
+	WebAddr  string `env:"SERVER_WEB_ADDR" envDefault:"0.0.0.0:8081"`  // gRPC-Web and Connect; empty disables
+
+	MetricsAddr string `env:"SERVER_METRICS_ADDR" envDefault:"0.0.0.0:9090"` // /debug/vars; empty disables
+
+	CORSAllowedOrigins []string      `env:"SERVER_CORS_ALLOWED_ORIGINS" envSeparator:","`
+	CORSMaxAge         time.Duration `env:"SERVER_CORS_MAX_AGE" envDefault:"10m"`
 }
@@ -1,8 +1,20 @@
 package config
 
+import (
+	"time"
+)
+
 type ServiceConfig struct {
-	UploadLimit   int64 `env:"SERVICE_UPLOAD_LIMIT" envDefault:"10"`
-	DownloadLimit int64 `env:"SERVICE_DOWNLOAD_LIMIT" envDefault:"10"`
-	ListLimit     int64 `env:"SERVICE_LIST_LIMIT" envDefault:"100"`
-	ChunkSize     int   `env:"SERVICE_CHUNK_SIZE" envDefault:"1048576"` // 1 MiB
+	UploadLimit         int64         `env:"SERVICE_UPLOAD_LIMIT" envDefault:"10"`
+	DownloadLimit       int64         `env:"SERVICE_DOWNLOAD_LIMIT" envDefault:"10"`
+	ListLimit           int64         `env:"SERVICE_LIST_LIMIT" envDefault:"100"`
+	ChunkSize           int           `env:"SERVICE_CHUNK_SIZE" envDefault:"1048576"`     // 1 MiB
+	MaxChunkSize        int           `env:"SERVICE_MAX_CHUNK_SIZE" envDefault:"2097152"` // 2 MiB
+	TrashRetention      time.Duration `env:"SERVICE_TRASH_RETENTION" envDefault:"168h"`
+	TrashPurgeInterval  time.Duration `env:"SERVICE_TRASH_PURGE_INTERVAL" envDefault:"1h"`
+	ExpiryInterval      time.Duration `env:"SERVICE_EXPIRY_INTERVAL" envDefault:"5m"`
+	ExpiryBatchSize     int           `env:"SERVICE_EXPIRY_BATCH_SIZE" envDefault:"500"`
+	ExtractMaxEntrySize int64         `env:"SERVICE_EXTRACT_MAX_ENTRY_SIZE" envDefault:"67108864"` // 64 MiB
+	ExtractMaxEntries   int           `env:"SERVICE_EXTRACT_MAX_ENTRIES" envDefault:"10000"`
+	ExtractMaxZipSize   int64         `env:"SERVICE_EXTRACT_MAX_ZIP_SIZE" envDefault:"1073741824"` // 1 GiB
 }
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+type WebhooksConfig struct {
+	File           string        `env:"WEBHOOKS_FILE" envDefault:""`
+	DeadLetterFile string        `env:"WEBHOOKS_DEAD_LETTER_FILE" envDefault:"webhooks-dead-letter.jsonl"`
+	QueueSize      int           `env:"WEBHOOKS_QUEUE_SIZE" envDefault:"1000"`
+	MaxAttempts    int           `env:"WEBHOOKS_MAX_ATTEMPTS" envDefault:"8"`
+	InitialBackoff time.Duration `env:"WEBHOOKS_INITIAL_BACKOFF" envDefault:"1s"`
+	MaxBackoff     time.Duration `env:"WEBHOOKS_MAX_BACKOFF" envDefault:"5m"`
+	Timeout        time.Duration `env:"WEBHOOKS_TIMEOUT" envDefault:"10s"`
+}
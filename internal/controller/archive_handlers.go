@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+var archiveFormats = map[pb.ArchiveFormat]service.ArchiveFormat{
+	pb.ArchiveFormat_ARCHIVE_FORMAT_ZIP:    service.ArchiveZip,
+	pb.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ: service.ArchiveTarGz,
+}
+
+func (h *FileServiceHandler) DownloadArchive(req *pb.DownloadArchiveRequest, stream pb.FileService_DownloadArchiveServer) error {
+	ctx := stream.Context()
+	l := logger.FromContext(ctx)
+	l.Infow("DownloadArchive called", "files", len(req.GetFilenames()), "prefix", req.GetPrefix(), "format", req.GetFormat())
+
+	format, ok := archiveFormats[req.GetFormat()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown archive format")
+	}
+
+	w := bufio.NewWriterSize(&archiveWriter{stream: stream}, bufferSize)
+	if err := h.service.DownloadArchive(ctx, req.GetFilenames(), req.GetPrefix(), format, w); err != nil {
+		l.Errorw("archive download error", "error", err)
+		return toStatus(err, codes.Internal, "archive download error")
+	}
+	if err := w.Flush(); err != nil {
+		l.Errorw("failed to send archive chunk", "error", err)
+		return status.Errorf(codes.Internal, "failed to send chunk")
+	}
+	l.Infow("DownloadArchive finished")
+	return nil
+}
+
+type archiveWriter struct {
+	stream pb.FileService_DownloadArchiveServer
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), bufferSize)
+		if err := w.stream.Send(&pb.DownloadArchiveResponse{Chunk: p[:n]}); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (h *FileServiceHandler) extractArchive(stream pb.FileService_UploadFileServer, filename string, body *uploadBody, prefix string, opts entity.UploadOptions) error {
+	ctx := body.ctx
+	l := logger.FromContext(ctx)
+	res, err := h.service.ExtractArchive(ctx, filename, body, prefix, opts)
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			l.Warnw("archive extraction aborted", "filename", filename, "cause", cause)
+			return abortStatus(cause)
+		}
+		l.Errorw("archive extraction failed", "filename", filename, "error", err)
+		return toStatus(err, codes.Internal, "archive extraction failed")
+	}
+	// Drain whatever the archive reader left unread so the client's stream
+	// completes normally.
+	_, _ = io.Copy(io.Discard, body)
+
+	resp := &pb.UploadFileResponse{
+		Message: fmt.Sprintf("archive '%s' extracted: %d created, %d failed", filename, len(res.Created), len(res.Failed)),
+	}
+	for _, c := range res.Created {
+		resp.Created = append(resp.Created, &pb.ExtractedFile{Name: c.Name, VersionId: c.VersionID, Size: c.Size})
+	}
+	for _, f := range res.Failed {
+		resp.Errors = append(resp.Errors, &pb.ExtractError{Entry: f.Name, Error: f.Error})
+	}
+	l.Infow("archive extracted", "filename", filename, "created", len(res.Created), "failed", len(res.Failed))
+	return stream.SendAndClose(resp)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"sync"
+)
+
+// batchMaxInFlight bounds the operations of one Batch stream that may wait on
+// the service semaphores at the same time, so a fast client cannot pile up
+// goroutines; the service limits still bound the actual storage work.
+const batchMaxInFlight = 64
+
+func (h *FileServiceHandler) Batch(stream pb.FileService_BatchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	l := logger.FromContext(ctx)
+	l.Info("Batch called")
+
+	results := make(chan *pb.BatchResponse)
+	sendErr := make(chan error, 1)
+	senderDone := make(chan struct{})
+	go func() {
+		defer close(senderDone)
+		failed := false
+		for res := range results {
+			if failed {
+				continue
+			}
+			if err := stream.Send(res); err != nil {
+				l.Errorw("failed to send batch result", "id", res.GetId(), "error", err)
+				sendErr <- err
+				failed = true
+				cancel()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, batchMaxInFlight)
+	received := 0
+	var recvErr error
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		received++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			results <- h.batchOp(ctx, req)
+		}()
+	}
+	wg.Wait()
+	close(results)
+	<-senderDone
+
+	select {
+	case err := <-sendErr:
+		return status.Errorf(codes.Internal, "failed to send batch result: %v", err)
+	default:
+	}
+	if recvErr != nil {
+		l.Errorw("batch receive error", "error", recvErr)
+		return status.Errorf(codes.Internal, "failed to receive batch operation")
+	}
+	if err := ctx.Err(); err != nil {
+		return status.Errorf(codes.Canceled, "request canceled")
+	}
+	l.Infow("Batch finished", "operations", received)
+	return nil
+}
+
+func (h *FileServiceHandler) batchOp(ctx context.Context, req *pb.BatchRequest) *pb.BatchResponse {
+	resp := &pb.BatchResponse{Id: req.GetId()}
+	var err error
+	switch op := req.GetOp().(type) {
+	case *pb.BatchRequest_Stat:
+		m, serr := h.service.StatFile(ctx, op.Stat.GetFilename())
+		if err = serr; err == nil {
+			resp.Result = &pb.BatchResponse_Stat{Stat: toPbFileMetadata(m)}
+		}
+	case *pb.BatchRequest_Delete:
+		item, derr := h.service.DeleteFile(ctx, op.Delete.GetFilename(), op.Delete.GetIfMatch())
+		if err = derr; err == nil {
+			resp.Result = &pb.BatchResponse_Delete{Delete: &pb.DeleteFileResponse{TrashId: item.ID}}
+		}
+	case *pb.BatchRequest_Copy:
+		info, cerr := h.service.CopyFile(ctx, op.Copy.GetSource(), op.Copy.GetDestination(), op.Copy.GetOverwrite())
+		if err = cerr; err == nil {
+			resp.Result = &pb.BatchResponse_Copy{Copy: &pb.CopyFileResponse{VersionId: info.VersionID}}
+		}
+	case *pb.BatchRequest_SetAttributes:
+		m, aerr := h.service.SetAttributes(ctx, op.SetAttributes.GetFilename(), op.SetAttributes.GetAttributes())
+		if err = aerr; err == nil {
+			resp.Result = &pb.BatchResponse_SetAttributes{SetAttributes: toPbFileMetadata(m)}
+		}
+	default:
+		resp.Code = int32(codes.InvalidArgument)
+		resp.Error = "unknown batch operation"
+		return resp
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warnw("batch operation failed", "id", req.GetId(), "error", err)
+		st := status.Convert(toStatus(err, codes.Internal, "operation failed"))
+		resp.Code = int32(st.Code())
+		resp.Error = st.Message()
+	}
+	return resp
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+)
+
+// memStorage serves one in-memory file and discards saved files; the other
+// storage methods are left unimplemented.
+type memStorage struct {
+	storage.FileStorage
+	data []byte
+}
+
+func (s memStorage) GetFileReader(_ context.Context, _ string, opts entity.ReadOptions) (io.ReadCloser, error) {
+	data := s.data[opts.Offset:]
+	if opts.Length > 0 {
+		data = data[:opts.Length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s memStorage) SaveFile(_ context.Context, _ string, r io.Reader, _ int64, _ entity.UploadOptions) (entity.UploadInfo, error) {
+	n, err := io.Copy(io.Discard, r)
+	return entity.UploadInfo{Size: n}, err
+}
+
+// newBenchClient serves the file service backed by stg over an in-process
+// connection.
+func newBenchClient(tb testing.TB, stg storage.FileStorage, opts ...grpc.ServerOption) pb.FileServiceClient {
+	tb.Helper()
+	svc := service.NewFileService(stg, 0, 0, 0, 0)
+	lis := bufconn.Listen(4 << 20)
+	srv := grpc.NewServer(opts...)
+	pb.RegisterFileServiceServer(srv, NewFileServiceHandler(svc))
+	go srv.Serve(lis)
+	tb.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	return pb.NewFileServiceClient(conn)
+}
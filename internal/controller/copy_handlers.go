@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *FileServiceHandler) CopyFile(ctx context.Context, req *pb.CopyFileRequest) (*pb.CopyFileResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("CopyFile called", "source", req.GetSource(), "destination", req.GetDestination(), "overwrite", req.GetOverwrite())
+	info, err := h.service.CopyFile(ctx, req.GetSource(), req.GetDestination(), req.GetOverwrite())
+	if err != nil {
+		l.Errorw("copy file error", "error", err)
+		return nil, toStatus(err, codes.Internal, "copy file error")
+	}
+	l.Infow("CopyFile finished", "destination", info.Name, "version_id", info.VersionID)
+	return &pb.CopyFileResponse{VersionId: info.VersionID}, nil
+}
+
+func (h *FileServiceHandler) MoveFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.MoveFileResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("MoveFile called", "source", req.GetSource(), "destination", req.GetDestination(), "overwrite", req.GetOverwrite())
+	info, err := h.service.MoveFile(ctx, req.GetSource(), req.GetDestination(), req.GetOverwrite())
+	if err != nil {
+		l.Errorw("move file error", "error", err)
+		return nil, toStatus(err, codes.Internal, "move file error")
+	}
+	l.Infow("MoveFile finished", "destination", info.Name, "version_id", info.VersionID)
+	return &pb.MoveFileResponse{VersionId: info.VersionID}, nil
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"io"
+	"testing"
+)
+
+// BenchmarkDownloadFile measures DownloadFile throughput end to end over an
+// in-process connection for a range of negotiated chunk sizes.
+func BenchmarkDownloadFile(b *testing.B) {
+	const fileSize = 16 << 20
+	client := newBenchClient(b, memStorage{data: make([]byte, fileSize)}, grpc.ForceServerCodecV2(Codec()))
+
+	for _, size := range []int{16 << 10, 64 << 10, 256 << 10, 1 << 20, 2 << 20} {
+		b.Run(fmt.Sprintf("chunk=%dKiB", size>>10), func(b *testing.B) {
+			b.SetBytes(fileSize)
+			b.ReportAllocs()
+			for range b.N {
+				stream, err := client.DownloadFile(context.Background(), &pb.DownloadFileRequest{Filename: "bench.bin", ChunkSize: int32(size)})
+				if err != nil {
+					b.Fatal(err)
+				}
+				var n int
+				for {
+					resp, err := stream.Recv()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+					n += len(resp.GetChunk())
+				}
+				if n != fileSize {
+					b.Fatalf("received %d bytes, want %d", n, fileSize)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"errors"
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func toStatus(err error, code codes.Code, msg string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case errors.Is(err, storage.ErrNotFound):
+		return status.Errorf(codes.NotFound, "file not found")
+	case errors.Is(err, storage.ErrInvalidRange):
+		return status.Errorf(codes.OutOfRange, "requested range is not satisfiable")
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return status.Errorf(codes.AlreadyExists, "file already exists")
+	case errors.Is(err, storage.ErrPreconditionFailed):
+		return status.Errorf(codes.FailedPrecondition, "file etag does not match")
+	case errors.Is(err, service.ErrFeatureDisabled):
+		return status.Errorf(codes.Unimplemented, "%s", err.Error())
+	case errors.Is(err, events.ErrCursorExpired):
+		return status.Errorf(codes.OutOfRange, "event cursor expired")
+	case errors.Is(err, events.ErrLagged):
+		return status.Errorf(codes.Aborted, "watcher fell behind; resume from the last received cursor")
+	case errors.Is(err, imaging.ErrTooLarge):
+		return status.Errorf(codes.FailedPrecondition, "image exceeds size limits")
+	case errors.Is(err, imaging.ErrUnsupportedFormat), errors.Is(err, imaging.ErrMalformedImage):
+		return status.Errorf(codes.FailedPrecondition, "file is not a supported image")
+	}
+	return status.Errorf(code, "%s", msg)
+}
+
+// abortStatus reports why a stream was aborted: the client's own status, or
+// Canceled or DeadlineExceeded when its context ended.
+func abortStatus(cause error) error {
+	if _, ok := status.FromError(cause); ok {
+		return cause
+	}
+	return status.FromContextError(cause).Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
 	pb "github.com/PianyCoder/test_file_service/internal/proto"
 	"github.com/PianyCoder/test_file_service/internal/service"
 
@@ -11,6 +12,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"io"
+	"time"
 )
 
 const bufferSize = 1024 * 64 // 64 KiB
@@ -43,102 +45,99 @@ func (h *FileServiceHandler) UploadFile(stream pb.FileService_UploadFileServer)
 		l.Warn("filename required in first message")
 		return status.Errorf(codes.InvalidArgument, "filename required in first message")
 	}
-	l.Infow("upload metadata received", "filename", filename)
-
-	pr, pw := io.Pipe()
-
-	go func(firstChunk []byte) {
-		defer func() {
-			_ = pw.Close()
-			l.Debug("uploader goroutine finished and pipe writer closed")
-		}()
-		if len(firstChunk) > 0 {
-			if _, werr := pw.Write(firstChunk); werr != nil {
-				_ = pw.CloseWithError(werr)
-				l.Errorw("failed to write first chunk to pipe", "error", werr)
-				return
-			}
-		}
-		for {
-			msg, rerr := stream.Recv()
-			if rerr != nil {
-				if rerr == io.EOF {
-					l.Debug("uploader goroutine received EOF from stream")
-					return
-				}
-				_ = pw.CloseWithError(rerr)
-				l.Errorw("error receiving chunk from stream", "error", rerr)
-				return
-			}
-			ch := msg.GetChunk()
-			if len(ch) > 0 {
-				if _, werr := pw.Write(ch); werr != nil {
-					_ = pw.CloseWithError(werr)
-					l.Errorw("failed to write chunk to pipe", "error", werr)
-					return
-				}
-			}
-		}
-	}(req.GetChunk())
+	opts := entity.UploadOptions{
+		IfNoneMatch:   req.GetIfNoneMatch(),
+		IfMatch:       req.GetIfMatch(),
+		StripMetadata: req.GetStripMetadata(),
+		AutoOrient:    req.GetAutoOrient(),
+		Compression:   req.GetCompression(),
+	}
+	switch {
+	case req.GetExpiresAt() != nil && req.GetTtlSeconds() != 0:
+		l.Warn("both expires_at and ttl_seconds set")
+		return status.Errorf(codes.InvalidArgument, "expires_at and ttl_seconds are mutually exclusive")
+	case req.GetTtlSeconds() < 0:
+		l.Warnw("negative ttl", "ttl_seconds", req.GetTtlSeconds())
+		return status.Errorf(codes.InvalidArgument, "ttl_seconds must be positive")
+	case req.GetTtlSeconds() > 0:
+		opts.ExpiresAt = time.Now().Add(time.Duration(req.GetTtlSeconds()) * time.Second)
+	case req.GetExpiresAt() != nil:
+		opts.ExpiresAt = req.GetExpiresAt().AsTime()
+	}
+	l.Infow("upload metadata received", "filename", filename, "if_none_match", opts.IfNoneMatch, "if_match", opts.IfMatch, "expires_at", opts.ExpiresAt, "compression", opts.Compression)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(context.Canceled)
+	body := receiveUpload(ctx, cancel, stream, req.GetChunk())
+	defer body.release()
 
-	if err := h.service.UploadFile(ctx, filename, pr); err != nil {
+	if req.GetExtract() {
+		return h.extractArchive(stream, filename, body, req.GetExtractTo(), opts)
+	}
+
+	info, err := h.service.UploadFile(ctx, filename, body, opts)
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			l.Warnw("upload aborted", "filename", filename, "cause", cause)
+			return abortStatus(cause)
+		}
 		l.Errorw("upload failed", "filename", filename, "error", err)
-		return status.Errorf(codes.Internal, "upload failed")
+		return toStatus(err, codes.Internal, "upload failed")
 	}
 
-	l.Infow("upload finished successfully", "filename", filename)
-	return stream.SendAndClose(&pb.UploadFileResponse{Message: fmt.Sprintf("file '%s' uploaded", filename)})
+	l.Infow("upload finished successfully", "filename", filename, "version_id", info.VersionID)
+	return stream.SendAndClose(&pb.UploadFileResponse{
+		Message:   fmt.Sprintf("file '%s' uploaded", filename),
+		VersionId: info.VersionID,
+		Etag:      info.ETag,
+	})
 }
 
 func (h *FileServiceHandler) DownloadFile(req *pb.DownloadFileRequest, stream pb.FileService_DownloadFileServer) error {
 	ctx := stream.Context()
 	l := logger.FromContext(ctx)
-	l.Infow("DownloadFile called", "req_filename", req.GetFilename())
+	l.Infow("DownloadFile called", "req_filename", req.GetFilename(), "offset", req.GetOffset(), "length", req.GetLength(), "chunk_size", req.GetChunkSize())
 
 	filename := req.GetFilename()
 	if filename == "" {
 		l.Warn("download filename required")
 		return status.Errorf(codes.InvalidArgument, "filename required")
 	}
+	if req.GetOffset() < 0 || req.GetLength() < 0 {
+		l.Warnw("invalid download range", "offset", req.GetOffset(), "length", req.GetLength())
+		return status.Errorf(codes.InvalidArgument, "offset and length must not be negative")
+	}
+	if req.GetChunkSize() < 0 {
+		l.Warnw("invalid chunk size", "chunk_size", req.GetChunkSize())
+		return status.Errorf(codes.InvalidArgument, "chunk_size must not be negative")
+	}
+	opts := entity.ReadOptions{
+		VersionID: req.GetVersionId(),
+		Offset:    req.GetOffset(),
+		Length:    req.GetLength(),
+		Raw:       req.GetRawEncoding(),
+		IfMatch:   req.GetIfMatch(),
+	}
 
-	pr, pw := io.Pipe()
-	go func() {
-		defer func() {
-			_ = pw.Close()
-			l.Debug("downloader goroutine finished and pipe writer closed")
-		}()
-		if err := h.service.DownloadFile(ctx, filename, pw); err != nil {
-			_ = pw.CloseWithError(err)
-			l.Errorw("service.DownloadFile error", "filename", filename, "error", err)
-			return
-		}
-	}()
-
-	buf := make([]byte, bufferSize)
-	for {
-		select {
-		case <-ctx.Done():
-			l.Warn("download context canceled", "filename", filename)
-			return status.Errorf(codes.Canceled, "request canceled")
-		default:
-		}
-
-		n, err := pr.Read(buf)
-		if n > 0 {
-			if sendErr := stream.Send(&pb.DownloadFileResponse{Chunk: buf[:n]}); sendErr != nil {
-				l.Errorw("failed to send chunk to client", "error", sendErr)
-				return status.Errorf(codes.Internal, "failed to send chunk")
-			}
-		}
-		if err != nil {
-			if err == io.EOF {
-				l.Infow("download finished", "filename", filename)
-				break
-			}
-			l.Errorw("read pipe error during download", "error", err, "filename", filename)
-			return status.Errorf(codes.Internal, "failed to read file")
-		}
+	var sendErr error
+	err := h.service.DownloadChunks(ctx, filename, opts, int(req.GetChunkSize()), func(chunk []byte) error {
+		// Send has encoded the message by the time it returns, so the
+		// service may reuse the chunk.
+		sendErr = stream.Send(&pb.DownloadFileResponse{Chunk: chunk})
+		return sendErr
+	})
+	switch {
+	case sendErr != nil:
+		l.Errorw("failed to send chunk to client", "error", sendErr)
+		return status.Errorf(codes.Internal, "failed to send chunk")
+	case ctx.Err() != nil:
+		l.Warnw("download context canceled", "filename", filename)
+		return status.Errorf(codes.Canceled, "request canceled")
+	case err != nil:
+		l.Errorw("download failed", "error", err, "filename", filename)
+		return toStatus(err, codes.Internal, "failed to read file")
 	}
+	l.Infow("download finished", "filename", filename)
 	return nil
 }
 
@@ -153,12 +152,81 @@ func (h *FileServiceHandler) ListFiles(ctx context.Context, req *pb.ListFilesReq
 
 	resp := &pb.ListFilesResponse{}
 	for _, m := range metadata {
-		resp.Files = append(resp.Files, &pb.FileMetadata{
-			Name:      m.Name,
-			CreatedAt: timestamppb.New(m.CreatedAt),
-			UpdatedAt: timestamppb.New(m.UpdatedAt),
-		})
+		resp.Files = append(resp.Files, toPbFileMetadata(m))
 	}
 	l.Infow("ListFiles finished", "count", len(resp.Files))
 	return resp, nil
 }
+
+func (h *FileServiceHandler) ListFileVersions(ctx context.Context, req *pb.ListFileVersionsRequest) (*pb.ListFileVersionsResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("ListFileVersions called", "filename", req.GetFilename())
+	versions, err := h.service.ListFileVersions(ctx, req.GetFilename())
+	if err != nil {
+		l.Errorw("list file versions error", "error", err)
+		return nil, toStatus(err, codes.Internal, "list file versions error")
+	}
+
+	resp := &pb.ListFileVersionsResponse{}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, &pb.FileVersion{
+			Name:           v.Name,
+			VersionId:      v.VersionID,
+			Size:           v.Size,
+			Etag:           v.ETag,
+			IsLatest:       v.IsLatest,
+			IsDeleteMarker: v.IsDeleteMarker,
+			UpdatedAt:      timestamppb.New(v.UpdatedAt),
+		})
+	}
+	l.Infow("ListFileVersions finished", "filename", req.GetFilename(), "count", len(resp.Versions))
+	return resp, nil
+}
+
+func (h *FileServiceHandler) RestoreFileVersion(ctx context.Context, req *pb.RestoreFileVersionRequest) (*pb.RestoreFileVersionResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("RestoreFileVersion called", "filename", req.GetFilename(), "version_id", req.GetVersionId())
+	info, err := h.service.RestoreFileVersion(ctx, req.GetFilename(), req.GetVersionId())
+	if err != nil {
+		l.Errorw("restore file version error", "error", err)
+		return nil, toStatus(err, codes.Internal, "restore file version error")
+	}
+	l.Infow("RestoreFileVersion finished", "filename", req.GetFilename(), "version_id", info.VersionID)
+	return &pb.RestoreFileVersionResponse{VersionId: info.VersionID}, nil
+}
+
+func toPbFileMetadata(m entity.FileMetadata) *pb.FileMetadata {
+	return &pb.FileMetadata{
+		Name:        m.Name,
+		CreatedAt:   timestamppb.New(m.CreatedAt),
+		UpdatedAt:   timestamppb.New(m.UpdatedAt),
+		ExpiresAt:   optionalTimestamp(m.ExpiresAt),
+		Size:        m.Size,
+		StoredSize:  m.StoredSize,
+		Compression: m.Compression,
+		Digest:      m.Digest,
+		Etag:        m.ETag,
+		ImageInfo:   toPbImageInfo(m.ImageInfo),
+		Attributes:  m.Attributes,
+	}
+}
+
+func toPbImageInfo(info *entity.ImageInfo) *pb.ImageInfo {
+	if info == nil {
+		return nil
+	}
+	return &pb.ImageInfo{
+		Format:      info.Format,
+		Width:       int32(info.Width),
+		Height:      int32(info.Height),
+		Orientation: int32(info.Orientation),
+		CapturedAt:  optionalTimestamp(info.CapturedAt),
+	}
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var fitModes = map[pb.FitMode]imaging.Fit{
+	pb.FitMode_FIT_MODE_CONTAIN: imaging.FitContain,
+	pb.FitMode_FIT_MODE_COVER:   imaging.FitCover,
+	pb.FitMode_FIT_MODE_FILL:    imaging.FitFill,
+}
+
+var imageFormats = map[pb.ImageFormat]imaging.Format{
+	pb.ImageFormat_IMAGE_FORMAT_JPEG: imaging.FormatJPEG,
+	pb.ImageFormat_IMAGE_FORMAT_PNG:  imaging.FormatPNG,
+	pb.ImageFormat_IMAGE_FORMAT_WEBP: imaging.FormatWebP,
+}
+
+func (h *FileServiceHandler) GetImageRendition(req *pb.GetImageRenditionRequest, stream pb.FileService_GetImageRenditionServer) error {
+	ctx := stream.Context()
+	l := logger.FromContext(ctx)
+	l.Infow("GetImageRendition called", "filename", req.GetFilename(), "width", req.GetWidth(), "height", req.GetHeight(), "fit", req.GetFit(), "format", req.GetFormat())
+
+	fit, ok := fitModes[req.GetFit()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown fit mode")
+	}
+	format, ok := imageFormats[req.GetFormat()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown image format")
+	}
+	if !imaging.CanEncode(format) {
+		l.Warnw("rendition format not available", "format", format)
+		return status.Errorf(codes.Unimplemented, "%s renditions are not available", format)
+	}
+	opts := imaging.Options{Width: int(req.GetWidth()), Height: int(req.GetHeight()), Fit: fit, Format: format}
+
+	w := &renditionWriter{stream: stream, contentType: format.ContentType()}
+	if err := h.service.GetImageRendition(ctx, req.GetFilename(), opts, w); err != nil {
+		l.Errorw("image rendition error", "filename", req.GetFilename(), "error", err)
+		return toStatus(err, codes.Internal, "image rendition error")
+	}
+	l.Infow("GetImageRendition finished", "filename", req.GetFilename())
+	return nil
+}
+
+type renditionWriter struct {
+	stream      pb.FileService_GetImageRenditionServer
+	contentType string
+}
+
+func (w *renditionWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), bufferSize)
+		msg := &pb.GetImageRenditionResponse{Chunk: p[:n], ContentType: w.contentType}
+		if err := w.stream.Send(msg); err != nil {
+			return written, err
+		}
+		w.contentType = ""
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
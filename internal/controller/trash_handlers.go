@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileServiceHandler) DeleteFile(ctx context.Context, req *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("DeleteFile called", "filename", req.GetFilename(), "if_match", req.GetIfMatch())
+	item, err := h.service.DeleteFile(ctx, req.GetFilename(), req.GetIfMatch())
+	if err != nil {
+		l.Errorw("delete file error", "error", err)
+		return nil, toStatus(err, codes.Internal, "delete file error")
+	}
+	l.Infow("DeleteFile finished", "filename", req.GetFilename(), "trash_id", item.ID)
+	return &pb.DeleteFileResponse{TrashId: item.ID}, nil
+}
+
+func (h *FileServiceHandler) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Info("ListTrash called")
+	items, err := h.service.ListTrash(ctx)
+	if err != nil {
+		l.Errorw("list trash error", "error", err)
+		return nil, toStatus(err, codes.Internal, "list trash error")
+	}
+
+	resp := &pb.ListTrashResponse{}
+	for _, it := range items {
+		resp.Items = append(resp.Items, &pb.TrashItem{
+			TrashId:      it.ID,
+			OriginalName: it.OriginalName,
+			Size:         it.Size,
+			DeletedAt:    timestamppb.New(it.DeletedAt),
+		})
+	}
+	l.Infow("ListTrash finished", "count", len(resp.Items))
+	return resp, nil
+}
+
+func (h *FileServiceHandler) RestoreFromTrash(ctx context.Context, req *pb.RestoreFromTrashRequest) (*pb.RestoreFromTrashResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("RestoreFromTrash called", "trash_id", req.GetTrashId(), "overwrite", req.GetOverwrite())
+	info, err := h.service.RestoreFromTrash(ctx, req.GetTrashId(), req.GetOverwrite())
+	if err != nil {
+		l.Errorw("restore from trash error", "error", err)
+		return nil, toStatus(err, codes.Internal, "restore from trash error")
+	}
+	l.Infow("RestoreFromTrash finished", "trash_id", req.GetTrashId(), "filename", info.Name)
+	return &pb.RestoreFromTrashResponse{Filename: info.Name, VersionId: info.VersionID}, nil
+}
+
+func (h *FileServiceHandler) EmptyTrash(ctx context.Context, req *pb.EmptyTrashRequest) (*pb.EmptyTrashResponse, error) {
+	l := logger.FromContext(ctx)
+	l.Info("EmptyTrash called")
+	n, err := h.service.EmptyTrash(ctx)
+	if err != nil {
+		l.Errorw("empty trash error", "error", err, "purged", n)
+		return nil, toStatus(err, codes.Internal, "empty trash error")
+	}
+	l.Infow("EmptyTrash finished", "purged", n)
+	return &pb.EmptyTrashResponse{Purged: int64(n)}, nil
+}
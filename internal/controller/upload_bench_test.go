@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"testing"
+)
+
+// BenchmarkUploadFile measures UploadFile throughput and server allocations
+// for a range of client chunk sizes, with the pooling codec and with the
+// plain proto codec for comparison.
+func BenchmarkUploadFile(b *testing.B) {
+	const fileSize = 16 << 20
+	codecs := []struct {
+		name string
+		opts []grpc.ServerOption
+	}{
+		{"pooled", []grpc.ServerOption{grpc.ForceServerCodecV2(Codec())}},
+		{"proto", nil},
+	}
+	for _, codec := range codecs {
+		client := newBenchClient(b, memStorage{}, codec.opts...)
+		for _, size := range []int{16 << 10, 64 << 10, 256 << 10, 1 << 20} {
+			b.Run(fmt.Sprintf("codec=%s/chunk=%dKiB", codec.name, size>>10), func(b *testing.B) {
+				chunk := make([]byte, size)
+				b.SetBytes(fileSize)
+				b.ReportAllocs()
+				for range b.N {
+					stream, err := client.UploadFile(context.Background())
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := stream.Send(&pb.UploadFileRequest{Filename: "bench.bin"}); err != nil {
+						b.Fatal(err)
+					}
+					for sent := 0; sent < fileSize; sent += size {
+						if err := stream.Send(&pb.UploadFileRequest{Chunk: chunk}); err != nil {
+							b.Fatal(err)
+						}
+					}
+					if _, err := stream.CloseAndRecv(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
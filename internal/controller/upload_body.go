@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/internal/bufpool"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"io"
+)
+
+// uploadReadAhead is how many received chunks may wait for the storage
+// write. When the queue is full the receiver stops, and gRPC flow control
+// then stops the client.
+const uploadReadAhead = 8
+
+// uploadBody presents the chunks of an upload stream as an io.Reader. A
+// receiver goroutine queues chunks while the storage write consumes them,
+// so that network receive and storage writes overlap.
+//
+// A receive error cancels ctx with the error as cause, so that a storage
+// write using ctx is aborted at once instead of on its next read.
+type uploadBody struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	chunks chan []byte
+	err    error // why chunks was closed, io.EOF at the end of the stream
+
+	cur []byte // unread part of chunk
+	buf []byte // chunk being read, returned to bufpool once consumed
+}
+
+// receiveUpload starts receiving the chunks following first. The receiver
+// may outlive the handler until gRPC cancels the stream context.
+func receiveUpload(ctx context.Context, cancel context.CancelCauseFunc, stream pb.FileService_UploadFileServer, first []byte) *uploadBody {
+	b := &uploadBody{ctx: ctx, cancel: cancel, chunks: make(chan []byte, uploadReadAhead)}
+	go b.receive(stream, first)
+	return b
+}
+
+func (b *uploadBody) receive(stream pb.FileService_UploadFileServer, first []byte) {
+	err := b.receiveAll(stream, first)
+	if err != io.EOF {
+		b.cancel(err)
+	}
+	b.err = err
+	close(b.chunks)
+	if err != io.EOF {
+		// Nobody reads past a failure; give the queued buffers back.
+		for chunk := range b.chunks {
+			bufpool.Put(chunk)
+		}
+	}
+}
+
+func (b *uploadBody) receiveAll(stream pb.FileService_UploadFileServer, first []byte) error {
+	if err := b.push(first); err != nil {
+		return err
+	}
+	var msg pb.UploadFileRequest
+	for {
+		if err := stream.RecvMsg(&msg); err != nil {
+			return err
+		}
+		chunk := msg.Chunk
+		msg.Chunk = nil
+		if err := b.push(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *uploadBody) push(chunk []byte) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+	select {
+	case b.chunks <- chunk:
+		return nil
+	case <-b.ctx.Done():
+		bufpool.Put(chunk)
+		return context.Cause(b.ctx)
+	}
+}
+
+func (b *uploadBody) Read(p []byte) (int, error) {
+	for len(b.cur) == 0 {
+		b.release()
+		select {
+		case chunk, ok := <-b.chunks:
+			if !ok {
+				return 0, b.err
+			}
+			b.buf, b.cur = chunk, chunk
+		case <-b.ctx.Done():
+			return 0, context.Cause(b.ctx)
+		}
+	}
+	n := copy(p, b.cur)
+	b.cur = b.cur[n:]
+	return n, nil
+}
+
+// release returns the chunk being read to the pool.
+func (b *uploadBody) release() {
+	if b.buf != nil {
+		bufpool.Put(b.buf)
+		b.buf, b.cur = nil, nil
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/bufpool"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/encoding"
+	grpcproto "google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+var chunkField = (&pb.UploadFileRequest{}).ProtoReflect().Descriptor().Fields().ByName("chunk").Number()
+
+// Codec returns the server codec for the file service. It is the standard
+// proto codec, except that the chunk of an UploadFileRequest is decoded into
+// a buffer from bufpool instead of a fresh allocation; UploadFile returns
+// the buffer once the chunk is stored.
+func Codec() encoding.CodecV2 {
+	return uploadCodec{CodecV2: encoding.GetCodecV2(grpcproto.Name)}
+}
+
+type uploadCodec struct {
+	encoding.CodecV2
+}
+
+func (c uploadCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	m, ok := v.(*pb.UploadFileRequest)
+	if !ok {
+		return c.CodecV2.Unmarshal(data, v)
+	}
+	buf := data.MaterializeToBuffer(mem.DefaultBufferPool())
+	defer buf.Free()
+	if err := unmarshalUpload(buf.ReadOnlyData(), m); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %w", v, err)
+	}
+	return nil
+}
+
+// unmarshalUpload decodes the chunk field itself and leaves the rest, which
+// only the first message of a stream carries, to proto.
+func unmarshalUpload(b []byte, m *pb.UploadFileRequest) error {
+	var chunk, rest []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		vn := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if vn < 0 {
+			return protowire.ParseError(vn)
+		}
+		if num == chunkField && typ == protowire.BytesType {
+			chunk, _ = protowire.ConsumeBytes(b[n:])
+		} else {
+			rest = append(rest, b[:n+vn]...)
+		}
+		b = b[n+vn:]
+	}
+
+	if err := proto.Unmarshal(rest, m); err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		m.Chunk = bufpool.Get(len(chunk))
+		copy(m.Chunk, chunk)
+	}
+	return nil
+}
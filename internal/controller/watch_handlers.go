@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var eventTypes = map[entity.EventType]pb.FileEventType{
+	entity.EventCreated: pb.FileEventType_FILE_EVENT_TYPE_CREATED,
+	entity.EventUpdated: pb.FileEventType_FILE_EVENT_TYPE_UPDATED,
+	entity.EventDeleted: pb.FileEventType_FILE_EVENT_TYPE_DELETED,
+}
+
+func (h *FileServiceHandler) WatchFiles(req *pb.WatchFilesRequest, stream pb.FileService_WatchFilesServer) error {
+	ctx := stream.Context()
+	l := logger.FromContext(ctx)
+	l.Infow("WatchFiles called", "prefix", req.GetPrefix(), "cursor", req.GetCursor())
+
+	send := func(e entity.FileEvent) error {
+		return stream.Send(&pb.FileEvent{
+			Cursor:    e.Cursor,
+			Type:      eventTypes[e.Type],
+			Name:      e.Name,
+			Size:      e.Size,
+			Etag:      e.ETag,
+			VersionId: e.VersionID,
+			Time:      timestamppb.New(e.Time),
+		})
+	}
+	err := h.service.WatchFiles(ctx, req.GetPrefix(), req.GetCursor(), send)
+	if err == nil || errors.Is(err, context.Canceled) {
+		l.Info("WatchFiles finished")
+		return nil
+	}
+	l.Errorw("watch files error", "error", err)
+	return toStatus(err, codes.Internal, "watch files error")
+}
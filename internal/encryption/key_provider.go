@@ -0,0 +1,96 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const DataKeySize = 32
+
+var ErrUnknownKey = errors.New("unknown key id")
+
+type KeyProvider interface {
+	GenerateDataKey(ctx context.Context) (plain, wrapped []byte, keyID string, err error)
+	UnwrapDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+type LocalKeyProvider struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+func NewLocalKeyProvider(path string) (*LocalKeyProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	master, err := parseMasterKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	return newLocalKeyProvider(master)
+}
+
+func newLocalKeyProvider(master []byte) (*LocalKeyProvider, error) {
+	block, err := aes.NewCipher(master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init master cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init master gcm: %w", err)
+	}
+	sum := sha256.Sum256(master)
+	return &LocalKeyProvider{keyID: "local:" + hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+func parseMasterKey(raw []byte) ([]byte, error) {
+	if len(raw) == DataKeySize {
+		return raw, nil
+	}
+	s := strings.TrimSpace(string(raw))
+	if b, err := hex.DecodeString(s); err == nil && len(b) == DataKeySize {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == DataKeySize {
+		return b, nil
+	}
+	return nil, fmt.Errorf("expected %d raw bytes, hex or base64 encoded key", DataKeySize)
+}
+
+func (p *LocalKeyProvider) GenerateDataKey(_ context.Context) ([]byte, []byte, string, error) {
+	plain := make([]byte, DataKeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate wrap nonce: %w", err)
+	}
+	wrapped := p.aead.Seal(nonce, nonce, plain, []byte(p.keyID))
+	return plain, wrapped, p.keyID, nil
+}
+
+func (p *LocalKeyProvider) UnwrapDataKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	ns := p.aead.NonceSize()
+	if len(wrapped) < ns {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	plain, err := p.aead.Open(nil, wrapped[:ns], wrapped[ns:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return plain, nil
+}
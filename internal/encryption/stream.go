@@ -0,0 +1,238 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Objects are sealed as a sequence of independent AES-GCM chunks. Chunk i uses
+// the big-endian counter i as nonce and a final-chunk flag as additional data,
+// so chunks can be decrypted in isolation (ranged reads) while reordering and
+// truncation are still detected.
+
+const (
+	Algorithm        = "AES-256-GCM-STREAM"
+	DefaultChunkSize = 64 * 1024
+	TagSize          = 16
+)
+
+var ErrCorrupted = errors.New("encrypted object is corrupted")
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(aead cipher.AEAD, index uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
+
+func chunkAAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func EncryptedSize(plainSize int64, chunkSize int) int64 {
+	cs := int64(chunkSize)
+	chunks := (plainSize + cs - 1) / cs
+	if chunks == 0 {
+		chunks = 1
+	}
+	return plainSize + chunks*TagSize
+}
+
+func PlainSize(encSize int64, chunkSize int) int64 {
+	ecs := int64(chunkSize + TagSize)
+	chunks := (encSize + ecs - 1) / ecs
+	return encSize - chunks*TagSize
+}
+
+// EncryptedRange maps a plaintext range onto the stored ciphertext; a
+// non-positive length means up to the end of the object. It returns
+// the ciphertext range to fetch, the index of its first chunk and the number of
+// leading plaintext bytes to discard.
+func EncryptedRange(offset, length, encSize int64, chunkSize int) (encOffset, encLength int64, firstChunk uint64, skip int64) {
+	cs, ecs := int64(chunkSize), int64(chunkSize+TagSize)
+	first := offset / cs
+	encOffset = first * ecs
+	encEnd := encSize
+	if length > 0 {
+		encEnd = ((offset+length-1)/cs + 1) * ecs
+	}
+	if encEnd > encSize {
+		encEnd = encSize
+	}
+	return encOffset, encEnd - encOffset, uint64(first), offset - first*cs
+}
+
+type encryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	index   uint64
+	plain   []byte
+	next    []byte
+	hasNext bool
+	sealed  []byte
+	out     []byte
+	done    bool
+	err     error
+}
+
+func NewEncryptReader(src io.Reader, key []byte, chunkSize int) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		src:   src,
+		aead:  aead,
+		plain: make([]byte, chunkSize),
+		next:  make([]byte, 1),
+	}, nil
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.done {
+			return 0, io.EOF
+		}
+		e.seal()
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encryptReader) seal() {
+	buf := e.plain[:0]
+	if e.hasNext {
+		buf = append(buf, e.next[0])
+		e.hasNext = false
+	}
+	n, err := io.ReadFull(e.src, e.plain[len(buf):])
+	buf = e.plain[:len(buf)+n]
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		e.err = err
+		return
+	default:
+		m, perr := io.ReadFull(e.src, e.next)
+		switch {
+		case m == 1:
+			e.hasNext = true
+		case perr == io.EOF:
+			final = true
+		default:
+			e.err = perr
+			return
+		}
+	}
+	e.sealed = e.aead.Seal(e.sealed[:0], chunkNonce(e.aead, e.index), buf, chunkAAD(final))
+	e.out = e.sealed
+	e.index++
+	e.done = final
+}
+
+type decryptReader struct {
+	src       io.Reader
+	aead      cipher.AEAD
+	index     uint64
+	lastIndex uint64
+	skip      int64
+	sealed    []byte
+	out       []byte
+	plain     []byte
+	err       error
+}
+
+// NewDecryptReader decrypts ciphertext starting at chunk firstChunk. encSize is
+// the size of the whole stored object, used to recognise its final chunk.
+func NewDecryptReader(src io.Reader, key []byte, chunkSize int, encSize int64, firstChunk uint64, skip int64) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	ecs := int64(chunkSize + TagSize)
+	total := (encSize + ecs - 1) / ecs
+	if total == 0 {
+		return nil, ErrCorrupted
+	}
+	return &decryptReader{
+		src:       src,
+		aead:      aead,
+		index:     firstChunk,
+		lastIndex: uint64(total - 1),
+		skip:      skip,
+		sealed:    make([]byte, ecs),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.open()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() {
+	if d.index > d.lastIndex {
+		d.err = io.EOF
+		return
+	}
+	n, err := io.ReadFull(d.src, d.sealed)
+	if err == io.EOF {
+		d.err = io.EOF
+		return
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		d.err = err
+		return
+	}
+	final := d.index == d.lastIndex
+	if err == io.ErrUnexpectedEOF && !final {
+		d.err = ErrCorrupted
+		return
+	}
+	plain, oerr := d.aead.Open(d.plain[:0], chunkNonce(d.aead, d.index), d.sealed[:n], chunkAAD(final))
+	if oerr != nil {
+		d.err = fmt.Errorf("%w: chunk %d: %v", ErrCorrupted, d.index, oerr)
+		return
+	}
+	d.plain = plain
+	d.index++
+	if d.skip > 0 {
+		if d.skip >= int64(len(plain)) {
+			d.skip -= int64(len(plain))
+			plain = nil
+		} else {
+			plain = plain[d.skip:]
+			d.skip = 0
+		}
+	}
+	d.out = plain
+	if final {
+		d.err = io.EOF
+	}
+}
@@ -0,0 +1,141 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+const testChunk = 10
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func plaintext(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 31)
+	}
+	return b
+}
+
+func seal(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	r, err := NewEncryptReader(bytes.NewReader(plain), testKey, testChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+func open(enc []byte, encSize int64, firstChunk uint64, skip int64) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(enc), testKey, testChunk, encSize, firstChunk, skip)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, testChunk - 1, testChunk, testChunk + 1, 3 * testChunk} {
+		plain := plaintext(size)
+		enc := seal(t, plain)
+		if got := EncryptedSize(int64(size), testChunk); got != int64(len(enc)) {
+			t.Errorf("size %d: EncryptedSize = %d, sealed %d bytes", size, got, len(enc))
+		}
+		got, err := open(enc, int64(len(enc)), 0, 0)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("size %d: opened %d bytes, err = %v", size, len(got), err)
+		}
+	}
+}
+
+func TestTamperingDetected(t *testing.T) {
+	ecs := testChunk + TagSize
+	enc := seal(t, plaintext(3*testChunk))
+	swap := func(i, j int) []byte {
+		b := bytes.Clone(enc)
+		copy(b[i*ecs:], enc[j*ecs:(j+1)*ecs])
+		copy(b[j*ecs:], enc[i*ecs:(i+1)*ecs])
+		return b
+	}
+	flipped := bytes.Clone(enc)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := []struct {
+		name string
+		enc  []byte
+	}{
+		{"final chunk dropped", enc[:2*ecs]},
+		{"final chunk cut short", enc[:len(enc)-1]},
+		{"final chunk swapped", swap(1, 2)},
+		{"leading chunks swapped", swap(0, 1)},
+		{"final tag flipped", flipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := open(tt.enc, int64(len(tt.enc)), 0, 0); !errors.Is(err, ErrCorrupted) {
+				t.Errorf("err = %v, want ErrCorrupted", err)
+			}
+		})
+	}
+}
+
+func TestPlainSize(t *testing.T) {
+	for _, size := range []int64{0, 1, testChunk - 1, testChunk, testChunk + 1, 2 * testChunk, 2*testChunk + 1} {
+		if got := PlainSize(EncryptedSize(size, testChunk), testChunk); got != size {
+			t.Errorf("PlainSize(EncryptedSize(%d)) = %d", size, got)
+		}
+	}
+}
+
+func TestEncryptedRange(t *testing.T) {
+	const ecs = testChunk + TagSize
+	plain := plaintext(3 * testChunk)
+	enc := seal(t, plain)
+	encSize := int64(len(enc))
+
+	tests := []struct {
+		offset, length int64
+		wantOffset     int64
+		wantLength     int64
+		wantChunk      uint64
+		wantSkip       int64
+	}{
+		{0, 0, 0, encSize, 0, 0},
+		{0, 1, 0, ecs, 0, 0},
+		{testChunk - 1, 1, 0, ecs, 0, testChunk - 1},
+		{testChunk - 1, 2, 0, 2 * ecs, 0, testChunk - 1},
+		{testChunk, 1, ecs, ecs, 1, 0},
+		{testChunk, testChunk, ecs, ecs, 1, 0},
+		{testChunk, testChunk + 1, ecs, 2 * ecs, 1, 0},
+		{2*testChunk + 5, 100, 2 * ecs, ecs, 2, 5},
+		{3*testChunk - 1, 0, 2 * ecs, ecs, 2, testChunk - 1},
+	}
+	for _, tt := range tests {
+		off, n, chunk, skip := EncryptedRange(tt.offset, tt.length, encSize, testChunk)
+		if off != tt.wantOffset || n != tt.wantLength || chunk != tt.wantChunk || skip != tt.wantSkip {
+			t.Errorf("EncryptedRange(%d, %d) = %d, %d, %d, %d, want %d, %d, %d, %d",
+				tt.offset, tt.length, off, n, chunk, skip, tt.wantOffset, tt.wantLength, tt.wantChunk, tt.wantSkip)
+			continue
+		}
+
+		// The range decrypts on its own to the requested plaintext.
+		got, err := open(enc[off:off+n], encSize, chunk, skip)
+		if err != nil {
+			t.Errorf("range (%d, %d): %v", tt.offset, tt.length, err)
+			continue
+		}
+		want := plain[tt.offset:]
+		if tt.length > 0 && tt.length < int64(len(want)) {
+			want = want[:tt.length]
+		}
+		if !bytes.HasPrefix(got, want) {
+			t.Errorf("range (%d, %d) = %v, want prefix %v", tt.offset, tt.length, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+type ExtractResult struct {
+	Created []UploadInfo
+	Failed  []EntryError
+}
+
+type EntryError struct {
+	Name  string
+	Error string
+}
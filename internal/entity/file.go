@@ -5,7 +5,15 @@ import (
 )
 
 type FileMetadata struct {
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Name        string
+	Size        int64 // logical size, as uploaded
+	StoredSize  int64 // bytes kept in the bucket after compression
+	Compression string
+	Digest      string // sha256 of the content when stored deduplicated
+	ETag        string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresAt   time.Time
+	ImageInfo   *ImageInfo
+	Attributes  map[string]string
 }
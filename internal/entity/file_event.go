@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+type FileEvent struct {
+	Cursor    string
+	Type      EventType
+	Name      string
+	Size      int64
+	ETag      string
+	VersionID string
+	Time      time.Time
+}
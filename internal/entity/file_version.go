@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+type FileVersion struct {
+	Name           string
+	VersionID      string
+	Size           int64
+	ETag           string
+	IsLatest       bool
+	IsDeleteMarker bool
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+)
+
+type ImageInfo struct {
+	Format      string
+	Width       int
+	Height      int
+	Orientation int // EXIF orientation tag, 1 (or 0 when unknown) means upright
+	CapturedAt  time.Time
+}
@@ -0,0 +1,9 @@
+package entity
+
+type ReadOptions struct {
+	VersionID string
+	Offset    int64
+	Length    int64  // <= 0 reads up to the end of the file
+	Raw       bool   // return compressed files in their stored encoding
+	IfMatch   string // fail unless the file has this etag
+}
@@ -0,0 +1,12 @@
+package entity
+
+import (
+	"time"
+)
+
+type TrashItem struct {
+	ID           string
+	OriginalName string
+	Size         int64
+	DeletedAt    time.Time
+}
@@ -0,0 +1,8 @@
+package entity
+
+type UploadInfo struct {
+	Name      string
+	VersionID string
+	ETag      string
+	Size      int64
+}
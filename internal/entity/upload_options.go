@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+)
+
+type UploadOptions struct {
+	IfNoneMatch string    // only "*" is supported: create the file only if it does not exist
+	IfMatch     string    // overwrite the file only if its current ETag matches
+	ExpiresAt   time.Time // zero means the file never expires
+	Compression string    // "" stores the content as is
+
+	StripMetadata bool // remove EXIF/XMP from JPEG and PNG uploads
+	AutoOrient    bool // rotate pixels according to the EXIF orientation tag
+
+	ImageInfo *ImageInfo // filled by the service for recognised images
+}
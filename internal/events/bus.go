@@ -0,0 +1,181 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCursorExpired = errors.New("event cursor expired")
+	ErrLagged        = errors.New("subscriber fell too far behind")
+)
+
+// Source identifies where events come from. The bus accepts events from one
+// source at a time, so that MinIO notifications and the in-process emitter
+// never report the same change twice.
+type Source int
+
+const (
+	SourceMinio Source = iota
+	SourceLocal
+)
+
+func (s Source) String() string {
+	if s == SourceLocal {
+		return "local"
+	}
+	return "minio"
+}
+
+const DefaultCapacity = 10000
+
+const subscriberBuffer = 256
+
+// Bus fans file events out to subscribers and keeps the most recent ones in a
+// ring buffer so that watchers can resume from a cursor. Cursors are
+// "<epoch>-<seq>"; the epoch changes on every restart, which invalidates
+// cursors handed out by a previous process.
+type Bus struct {
+	mu     sync.Mutex
+	epoch  string
+	ring   []entity.FileEvent
+	next   uint64
+	subs   map[*Subscription]struct{}
+	source Source
+}
+
+func NewBus(capacity int, source Source) *Bus {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Bus{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		ring:   make([]entity.FileEvent, capacity),
+		next:   1,
+		subs:   make(map[*Subscription]struct{}),
+		source: source,
+	}
+}
+
+func (b *Bus) SetSource(s Source) {
+	b.mu.Lock()
+	b.source = s
+	b.mu.Unlock()
+}
+
+func (b *Bus) Source() Source {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.source
+}
+
+// Publish records e if it comes from the active source and delivers it to
+// matching subscribers. Subscribers whose buffer is full are dropped with
+// ErrLagged rather than slowing down the publisher.
+func (b *Bus) Publish(src Source, e entity.FileEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if src != b.source {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	seq := b.next
+	b.next++
+	e.Cursor = b.epoch + "-" + strconv.FormatUint(seq, 10)
+	b.ring[seq%uint64(len(b.ring))] = e
+
+	for sub := range b.subs {
+		if !strings.HasPrefix(e.Name, sub.prefix) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.err = ErrLagged
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe returns a subscription to events whose name starts with prefix.
+// With a non-empty cursor, buffered events after it are replayed first.
+func (b *Bus) Subscribe(cursor, prefix string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := b.next
+	if cursor != "" {
+		seq, err := b.parseCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		from = seq + 1
+	}
+
+	var replay []entity.FileEvent
+	for seq := from; seq < b.next; seq++ {
+		if e := b.ring[seq%uint64(len(b.ring))]; strings.HasPrefix(e.Name, prefix) {
+			replay = append(replay, e)
+		}
+	}
+	sub := &Subscription{bus: b, prefix: prefix, ch: make(chan entity.FileEvent, len(replay)+subscriberBuffer)}
+	for _, e := range replay {
+		sub.ch <- e
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (b *Bus) parseCursor(cursor string) (uint64, error) {
+	epoch, s, ok := strings.Cut(cursor, "-")
+	if !ok || epoch != b.epoch {
+		return 0, fmt.Errorf("%w: %s", ErrCursorExpired, cursor)
+	}
+	seq, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || seq >= b.next {
+		return 0, fmt.Errorf("%w: %s", ErrCursorExpired, cursor)
+	}
+	if oldest := b.next - min(b.next-1, uint64(len(b.ring))); seq+1 < oldest {
+		return 0, fmt.Errorf("%w: %s", ErrCursorExpired, cursor)
+	}
+	return seq, nil
+}
+
+// remove must be called with b.mu held.
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+type Subscription struct {
+	bus    *Bus
+	prefix string
+	ch     chan entity.FileEvent
+	err    error
+}
+
+// Events is closed when the subscription ends; Err then tells why.
+func (s *Subscription) Events() <-chan entity.FileEvent {
+	return s.ch
+}
+
+func (s *Subscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
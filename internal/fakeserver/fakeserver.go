@@ -0,0 +1,286 @@
+// Package fakeserver is an in-memory FileService for testing clients over a
+// bufconn connection. It keeps files as whole byte slices and honours the
+// conditions of uploads, downloads and deletes the way the real server does.
+package fakeserver
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is safe for concurrent use once serving; set the failure fields
+// before making the calls they are meant for.
+type Server struct {
+	pb.UnimplementedFileServiceServer
+
+	// FailUploads and FailDownloads make that many calls fail with
+	// Unavailable, downloads after sending one chunk. LoseUploads makes
+	// that many uploads store the file and fail all the same, as if the
+	// response was lost. OnFail, if set, runs whenever a call fails so.
+	FailUploads   int
+	FailDownloads int
+	LoseUploads   int
+	OnFail        func()
+	// OnList, if set, runs after every ListFiles call, so that files can be
+	// changed between a listing and the calls based on it.
+	OnList func()
+
+	mu          sync.Mutex
+	files       map[string]file
+	trash       []string
+	uploadCalls int
+	chunkSizes  []int
+}
+
+type file struct {
+	data    []byte
+	updated time.Time
+}
+
+func New() *Server {
+	return &Server{files: make(map[string]file)}
+}
+
+// Dial serves s over bufconn until the test ends and returns a connection
+// to it.
+func Dial(t testing.TB, s *Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterFileServiceServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// ETag returns the ETag the server gives a file holding data.
+func ETag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Put stores data as name, as another writer would.
+func (s *Server) Put(name string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = file{data: data, updated: time.Now()}
+}
+
+func (s *Server) Get(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[name]
+	return f.data, ok
+}
+
+// Trashed returns the names of the deleted files in the order of deletion.
+func (s *Server) Trashed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.trash...)
+}
+
+func (s *Server) UploadCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploadCalls
+}
+
+// ChunkSizes returns the size of every upload chunk received.
+func (s *Server) ChunkSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.chunkSizes...)
+}
+
+func (s *Server) fail() {
+	if s.OnFail != nil {
+		s.OnFail()
+	}
+}
+
+func (s *Server) UploadFile(stream grpc.ClientStreamingServer[pb.UploadFileRequest, pb.UploadFileResponse]) error {
+	var name, ifNoneMatch, ifMatch string
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetFilename() != "" {
+			name = req.GetFilename()
+			ifNoneMatch, ifMatch = req.GetIfNoneMatch(), req.GetIfMatch()
+		}
+		if len(req.GetChunk()) > 0 {
+			s.mu.Lock()
+			s.chunkSizes = append(s.chunkSizes, len(req.GetChunk()))
+			s.mu.Unlock()
+		}
+		data = append(data, req.GetChunk()...)
+	}
+
+	s.mu.Lock()
+	s.uploadCalls++
+	if s.FailUploads > 0 {
+		s.FailUploads--
+		s.mu.Unlock()
+		s.fail()
+		return status.Error(codes.Unavailable, "try again")
+	}
+	cur, exists := s.files[name]
+	if ifNoneMatch == "*" && exists {
+		s.mu.Unlock()
+		return status.Error(codes.AlreadyExists, "file already exists")
+	}
+	if ifMatch != "" && (!exists || ETag(cur.data) != ifMatch) {
+		s.mu.Unlock()
+		return status.Error(codes.FailedPrecondition, "file etag does not match")
+	}
+	s.files[name] = file{data: data, updated: time.Now()}
+	lose := s.LoseUploads > 0
+	if lose {
+		s.LoseUploads--
+	}
+	s.mu.Unlock()
+	if lose {
+		s.fail()
+		return status.Error(codes.Unavailable, "connection reset")
+	}
+	return stream.SendAndClose(&pb.UploadFileResponse{VersionId: "v1", Etag: ETag(data)})
+}
+
+func (s *Server) DownloadFile(req *pb.DownloadFileRequest, stream grpc.ServerStreamingServer[pb.DownloadFileResponse]) error {
+	s.mu.Lock()
+	f, ok := s.files[req.GetFilename()]
+	fail := s.FailDownloads > 0
+	if fail {
+		s.FailDownloads--
+	}
+	s.mu.Unlock()
+	if fail {
+		s.fail()
+	}
+	if !ok {
+		return status.Error(codes.NotFound, "no such file")
+	}
+	if m := req.GetIfMatch(); m != "" && m != ETag(f.data) {
+		return status.Error(codes.FailedPrecondition, "file etag does not match")
+	}
+
+	data := f.data[min(req.GetOffset(), int64(len(f.data))):]
+	if n := req.GetLength(); n > 0 && n < int64(len(data)) {
+		data = data[:n]
+	}
+	for len(data) > 0 {
+		n := min(len(data), 1000)
+		if err := stream.Send(&pb.DownloadFileResponse{Chunk: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+		if fail {
+			return status.Error(codes.Unavailable, "connection reset")
+		}
+	}
+	return nil
+}
+
+func (s *Server) ListFiles(context.Context, *pb.ListFilesRequest) (*pb.ListFilesResponse, error) {
+	s.mu.Lock()
+	var resp pb.ListFilesResponse
+	for name, f := range s.files {
+		resp.Files = append(resp.Files, s.metadata(name, f))
+	}
+	s.mu.Unlock()
+	sort.Slice(resp.Files, func(i, j int) bool { return resp.Files[i].Name < resp.Files[j].Name })
+	if s.OnList != nil {
+		s.OnList()
+	}
+	return &resp, nil
+}
+
+func (s *Server) metadata(name string, f file) *pb.FileMetadata {
+	return &pb.FileMetadata{
+		Name:      name,
+		Size:      int64(len(f.data)),
+		Etag:      ETag(f.data),
+		Digest:    digest(f.data),
+		UpdatedAt: timestamppb.New(f.updated),
+	}
+}
+
+func (s *Server) DeleteFile(_ context.Context, req *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := req.GetFilename()
+	f, ok := s.files[name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "file not found")
+	}
+	if m := req.GetIfMatch(); m != "" && m != ETag(f.data) {
+		return nil, status.Error(codes.FailedPrecondition, "file etag does not match")
+	}
+	delete(s.files, name)
+	s.trash = append(s.trash, name)
+	return &pb.DeleteFileResponse{TrashId: strconv.Itoa(len(s.trash)) + "/" + name}, nil
+}
+
+func (s *Server) Batch(stream grpc.BidiStreamingServer[pb.BatchRequest, pb.BatchResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := req.GetStat().GetFilename()
+		s.mu.Lock()
+		f, ok := s.files[name]
+		resp := &pb.BatchResponse{Id: req.GetId()}
+		if ok {
+			resp.Result = &pb.BatchResponse_Stat{Stat: s.metadata(name, f)}
+		} else {
+			resp.Code = int32(codes.NotFound)
+			resp.Error = "no such file"
+		}
+		s.mu.Unlock()
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
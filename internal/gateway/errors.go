@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"errors"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"net/http"
+)
+
+// toHTTPStatus is the HTTP counterpart of the controller's toStatus.
+func toHTTPStatus(err error, code int, msg string) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidArgument):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound, "file not found"
+	case errors.Is(err, storage.ErrInvalidRange):
+		return http.StatusRequestedRangeNotSatisfiable, "requested range is not satisfiable"
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return http.StatusPreconditionFailed, "file already exists"
+	case errors.Is(err, storage.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, "file etag does not match"
+	case errors.Is(err, service.ErrFeatureDisabled):
+		return http.StatusNotImplemented, err.Error()
+	case errors.Is(err, imaging.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, "image exceeds size limits"
+	case errors.Is(err, imaging.ErrUnsupportedFormat), errors.Is(err, imaging.ErrMalformedImage):
+		return http.StatusUnprocessableEntity, "file is not a supported image"
+	}
+	return code, msg
+}
+
+func writeError(w http.ResponseWriter, err error, code int, msg string) {
+	code, msg = toHTTPStatus(err, code, msg)
+	http.Error(w, msg, code)
+}
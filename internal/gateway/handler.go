@@ -0,0 +1,257 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/service"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler serves the file service over plain HTTP. It calls the same
+// service instance as the gRPC controller, so both share its limits.
+type Handler struct {
+	service service.FileService
+	mux     *http.ServeMux
+}
+
+func NewHandler(svc service.FileService) *Handler {
+	h := &Handler{service: svc, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /files", h.listFiles)
+	h.mux.HandleFunc("GET /files/{name...}", h.getFile) // also serves HEAD
+	h.mux.HandleFunc("PUT /files/{name...}", h.putFile)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type fileJSON struct {
+	Name        string            `json:"name"`
+	Size        int64             `json:"size"`
+	ETag        string            `json:"etag"`
+	Compression string            `json:"compression,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+type uploadJSON struct {
+	Name      string `json:"name"`
+	VersionID string `json:"version_id,omitempty"`
+	ETag      string `json:"etag"`
+	Size      int64  `json:"size"`
+}
+
+func (h *Handler) listFiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	prefix := r.URL.Query().Get("prefix")
+	l.Infow("HTTP list files called", "prefix", prefix)
+
+	metadata, err := h.service.ListFiles(ctx)
+	if err != nil {
+		l.Errorw("list files error", "error", err)
+		writeError(w, err, http.StatusInternalServerError, "list files error")
+		return
+	}
+	files := make([]fileJSON, 0, len(metadata))
+	for _, m := range metadata {
+		if !strings.HasPrefix(m.Name, prefix) {
+			continue
+		}
+		f := fileJSON{
+			Name:        m.Name,
+			Size:        m.Size,
+			ETag:        m.ETag,
+			Compression: m.Compression,
+			CreatedAt:   m.CreatedAt,
+			UpdatedAt:   m.UpdatedAt,
+			Attributes:  m.Attributes,
+		}
+		if !m.ExpiresAt.IsZero() {
+			f.ExpiresAt = &m.ExpiresAt
+		}
+		files = append(files, f)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"files": files})
+	l.Infow("HTTP list files finished", "prefix", prefix, "count", len(files))
+}
+
+// getAttempts bounds how often getFile starts over when the file is
+// overwritten between its stat and the read of its body.
+const getAttempts = 3
+
+func (h *Handler) getFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	name := r.PathValue("name")
+	l.Infow("HTTP get file called", "method", r.Method, "filename", name, "range", r.Header.Get("Range"))
+
+	for attempt := 1; ; attempt++ {
+		err := h.serveFile(w, r, name)
+		if errors.Is(err, storage.ErrPreconditionFailed) && attempt < getAttempts {
+			l.Warnw("file changed while it was read, starting over", "filename", name, "attempt", attempt)
+			continue
+		}
+		if err != nil {
+			// The headers describe the stat that no longer holds.
+			for _, k := range []string{"ETag", "Last-Modified", "Accept-Ranges", "Content-Range", "Content-Length"} {
+				w.Header().Del(k)
+			}
+			writeError(w, err, http.StatusInternalServerError, "download failed")
+		}
+		return
+	}
+}
+
+// serveFile answers a GET or HEAD from one stat of the file. The body is
+// read on the condition that the file still has the etag of that stat, and
+// the status line is sent with its first byte, so that the headers never go
+// out with the body of another version. An error is returned only while
+// nothing is sent yet.
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request, name string) error {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	meta, err := h.service.StatFile(ctx, name)
+	if err != nil {
+		l.Errorw("stat file error", "error", err, "filename", name)
+		return err
+	}
+
+	etag := quoteETag(meta.ETag)
+	hdr := w.Header()
+	hdr.Set("ETag", etag)
+	hdr.Set("Last-Modified", meta.UpdatedAt.UTC().Format(http.TimeFormat))
+	hdr.Set("Accept-Ranges", "bytes")
+	hdr.Del("Content-Range")
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		hdr.Set("Content-Type", ct)
+	} else {
+		hdr.Set("Content-Type", "application/octet-stream")
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagListMatches(inm, meta.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	opts := entity.ReadOptions{IfMatch: meta.ETag}
+	code := http.StatusOK
+	length := meta.Size
+	if rh := r.Header.Get("Range"); rh != "" && ifRangeMatches(r.Header.Get("If-Range"), meta.ETag) {
+		offset, n, ok, err := parseRange(rh, meta.Size)
+		if errors.Is(err, errUnsatisfiable) {
+			l.Warnw("unsatisfiable range", "range", rh, "size", meta.Size, "filename", name)
+			hdr.Set("Content-Range", "bytes */"+strconv.FormatInt(meta.Size, 10))
+			http.Error(w, "requested range is not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return nil
+		}
+		if ok {
+			opts.Offset, opts.Length = offset, n
+			code, length = http.StatusPartialContent, n
+			hdr.Set("Content-Range", "bytes "+strconv.FormatInt(offset, 10)+"-"+
+				strconv.FormatInt(offset+n-1, 10)+"/"+strconv.FormatInt(meta.Size, 10))
+		}
+	}
+	hdr.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	if r.Method == http.MethodHead || length == 0 {
+		w.WriteHeader(code)
+		return nil
+	}
+	body := &bodyWriter{w: w, code: code}
+	if err := h.service.DownloadFile(ctx, name, opts, body); err != nil {
+		if !body.started {
+			return err
+		}
+		// The status line is already sent; the short body tells the client.
+		l.Errorw("HTTP download failed", "error", err, "filename", name)
+		return nil
+	}
+	l.Infow("HTTP get file finished", "filename", name, "bytes", length)
+	return nil
+}
+
+// bodyWriter sends the status line with the first byte of the body.
+type bodyWriter struct {
+	w       http.ResponseWriter
+	code    int
+	started bool
+}
+
+func (b *bodyWriter) Write(p []byte) (int, error) {
+	if !b.started {
+		b.started = true
+		b.w.WriteHeader(b.code)
+	}
+	return b.w.Write(p)
+}
+
+func (h *Handler) putFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	name := r.PathValue("name")
+	opts := entity.UploadOptions{
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+		IfMatch:     r.Header.Get("If-Match"),
+	}
+	l.Infow("HTTP put file called", "filename", name, "if_none_match", opts.IfNoneMatch, "if_match", opts.IfMatch)
+
+	info, err := h.service.UploadFile(ctx, name, r.Body, opts)
+	if err != nil {
+		l.Errorw("HTTP upload failed", "error", err, "filename", name)
+		writeError(w, err, http.StatusInternalServerError, "upload failed")
+		return
+	}
+	w.Header().Set("ETag", quoteETag(info.ETag))
+	writeJSON(w, http.StatusOK, uploadJSON{Name: name, VersionID: info.VersionID, ETag: info.ETag, Size: info.Size})
+	l.Infow("HTTP put file finished", "filename", name, "version_id", info.VersionID)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func quoteETag(etag string) string {
+	return `"` + unquoteETag(etag) + `"`
+}
+
+func unquoteETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+}
+
+// etagListMatches implements the weak comparison If-None-Match asks for.
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || unquoteETag(candidate) == unquoteETag(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ifRangeMatches reports whether a Range header should be honoured. Only the
+// entity-tag form of If-Range is supported; a date never matches, so the
+// whole file is sent, which is always a correct answer.
+func ifRangeMatches(header, etag string) bool {
+	if header == "" {
+		return true
+	}
+	if strings.HasPrefix(header, "W/") || !strings.HasPrefix(header, `"`) {
+		return false
+	}
+	return unquoteETag(header) == unquoteETag(etag)
+}
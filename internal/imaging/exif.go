@@ -0,0 +1,138 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+
+	exifTimeLayout = "2006:01:02 15:04:05"
+)
+
+var errBadExif = errors.New("malformed exif data")
+
+type exifFields struct {
+	orientation int
+	width       int
+	height      int
+	capturedAt  time.Time
+}
+
+// parseExif reads the few fields we expose from a TIFF-structured EXIF blob.
+func parseExif(tiff []byte) (exifFields, error) {
+	var f exifFields
+	if len(tiff) < 8 {
+		return f, errBadExif
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return f, errBadExif
+	}
+	if bo.Uint16(tiff[2:]) != 42 {
+		return f, errBadExif
+	}
+
+	var dateTime, dateTimeOriginal string
+	exifIFD := uint32(0)
+	visit := func(offset uint32, fn func(tag, typ uint16, count uint32, value []byte)) error {
+		if int(offset)+2 > len(tiff) {
+			return errBadExif
+		}
+		n := int(bo.Uint16(tiff[offset:]))
+		for i := 0; i < n; i++ {
+			e := int(offset) + 2 + i*12
+			if e+12 > len(tiff) {
+				return errBadExif
+			}
+			tag, typ, count := bo.Uint16(tiff[e:]), bo.Uint16(tiff[e+2:]), bo.Uint32(tiff[e+4:])
+			value := tiff[e+8 : e+12]
+			if size := typeSize(typ) * int(count); size > 4 {
+				off := int(bo.Uint32(value))
+				if size < 0 || off < 0 || off+size > len(tiff) {
+					continue
+				}
+				value = tiff[off : off+size]
+			}
+			fn(tag, typ, count, value)
+		}
+		return nil
+	}
+	readInt := func(typ uint16, v []byte) int {
+		switch typ {
+		case 3:
+			return int(bo.Uint16(v))
+		case 4:
+			return int(bo.Uint32(v))
+		}
+		return 0
+	}
+
+	err := visit(bo.Uint32(tiff[4:]), func(tag, typ uint16, count uint32, v []byte) {
+		switch tag {
+		case tagOrientation:
+			f.orientation = readInt(typ, v)
+		case tagDateTime:
+			dateTime = asciiValue(v)
+		case tagExifIFD:
+			exifIFD = uint32(readInt(typ, v))
+		}
+	})
+	if err != nil {
+		return f, err
+	}
+	if exifIFD != 0 {
+		_ = visit(exifIFD, func(tag, typ uint16, count uint32, v []byte) {
+			switch tag {
+			case tagDateTimeOriginal:
+				dateTimeOriginal = asciiValue(v)
+			case tagPixelXDimension:
+				f.width = readInt(typ, v)
+			case tagPixelYDimension:
+				f.height = readInt(typ, v)
+			}
+		})
+	}
+
+	for _, s := range []string{dateTimeOriginal, dateTime} {
+		if t, err := time.Parse(exifTimeLayout, s); err == nil {
+			f.capturedAt = t
+			break
+		}
+	}
+	if f.orientation < 1 || f.orientation > 8 {
+		f.orientation = 0
+	}
+	return f, nil
+}
+
+func typeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 0
+}
+
+func asciiValue(v []byte) string {
+	return strings.TrimRight(string(v), "\x00 ")
+}
@@ -0,0 +1,280 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"io"
+)
+
+var (
+	jpegMagic = []byte{0xFF, 0xD8, 0xFF}
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+
+	exifPrefix = []byte("Exif\x00\x00")
+	xmpPrefix  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExt     = []byte("http://ns.adobe.com/xmp/extension/\x00")
+
+	ErrMalformedImage = errors.New("malformed image")
+)
+
+const (
+	maxPNGHeaderChunk = 8 << 20
+	// maxHeaderBytes bounds everything buffered before the pixel data, so
+	// many small segments cannot add up to an unbounded header.
+	maxHeaderBytes = 16 << 20
+)
+
+// Inspect sniffs JPEG and PNG content and extracts image info from the headers
+// that precede the pixel data, so the rest of the stream is never buffered.
+// With strip set, EXIF, XMP and textual metadata are dropped from the stream.
+// Other content is passed through untouched with a nil info.
+func Inspect(r io.Reader, strip bool) (io.Reader, *entity.ImageInfo, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(pngMagic))
+	rec := &recorder{r: br}
+
+	var info *entity.ImageInfo
+	var rest io.Reader
+	var err error
+	switch {
+	case bytes.HasPrefix(magic, jpegMagic):
+		info, err = inspectJPEG(rec, strip)
+		rest = br
+	case bytes.Equal(magic, pngMagic):
+		var remaining int64
+		info, remaining, err = inspectPNG(rec, strip)
+		rest = br
+		if strip {
+			rest = &pngFilter{r: br, remaining: remaining}
+		}
+	default:
+		return br, nil, nil
+	}
+	if err != nil {
+		if strip {
+			return nil, nil, err
+		}
+		return io.MultiReader(bytes.NewReader(rec.raw.Bytes()), br), nil, nil
+	}
+
+	head := rec.raw.Bytes()
+	if strip {
+		head = rec.out.Bytes()
+	}
+	return io.MultiReader(bytes.NewReader(head), rest), info, nil
+}
+
+// recorder keeps every consumed byte (raw) and the subset that survives
+// stripping (out).
+type recorder struct {
+	r   *bufio.Reader
+	raw bytes.Buffer
+	out bytes.Buffer
+}
+
+func (rc *recorder) read(n int) ([]byte, error) {
+	if rc.raw.Len()+n > maxHeaderBytes {
+		return nil, fmt.Errorf("%w: headers larger than %d bytes", ErrMalformedImage, maxHeaderBytes)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rc.r, buf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedImage, err)
+	}
+	rc.raw.Write(buf)
+	return buf, nil
+}
+
+func (rc *recorder) keep(parts ...[]byte) {
+	for _, p := range parts {
+		rc.out.Write(p)
+	}
+}
+
+func inspectJPEG(rc *recorder, strip bool) (*entity.ImageInfo, error) {
+	soi, err := rc.read(2)
+	if err != nil {
+		return nil, err
+	}
+	rc.keep(soi)
+	info := &entity.ImageInfo{Format: string(FormatJPEG)}
+
+	for {
+		m, err := rc.read(2)
+		if err != nil {
+			return nil, err
+		}
+		for m[0] == 0xFF && m[1] == 0xFF {
+			b, err := rc.read(1)
+			if err != nil {
+				return nil, err
+			}
+			m = []byte{0xFF, b[0]}
+		}
+		if m[0] != 0xFF {
+			return nil, fmt.Errorf("%w: bad jpeg marker", ErrMalformedImage)
+		}
+		marker := m[1]
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD8) {
+			rc.keep(m)
+			continue
+		}
+		if marker == 0xD9 {
+			rc.keep(m)
+			return info, nil
+		}
+
+		l, err := rc.read(2)
+		if err != nil {
+			return nil, err
+		}
+		size := int(binary.BigEndian.Uint16(l))
+		if size < 2 {
+			return nil, fmt.Errorf("%w: bad jpeg segment length", ErrMalformedImage)
+		}
+		payload, err := rc.read(size - 2)
+		if err != nil {
+			return nil, err
+		}
+
+		drop := false
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifPrefix):
+			if f, err := parseExif(payload[len(exifPrefix):]); err == nil {
+				info.Orientation = f.orientation
+				info.CapturedAt = f.capturedAt
+				if info.Width == 0 {
+					info.Width, info.Height = f.width, f.height
+				}
+			}
+			drop = strip
+		case marker == 0xE1 && (bytes.HasPrefix(payload, xmpPrefix) || bytes.HasPrefix(payload, xmpExt)):
+			drop = strip
+		case marker == 0xED || marker == 0xFE:
+			// Photoshop IRB (IPTC) and free-form comments
+			drop = strip
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			if len(payload) >= 5 {
+				info.Height = int(binary.BigEndian.Uint16(payload[1:]))
+				info.Width = int(binary.BigEndian.Uint16(payload[3:]))
+			}
+		}
+		if !drop {
+			rc.keep(m, l, payload)
+		}
+		if marker == 0xDA {
+			// start of scan: entropy-coded data follows and is streamed as is
+			return info, nil
+		}
+	}
+}
+
+func isPNGMetadataChunk(typ string) bool {
+	switch typ {
+	case "eXIf", "iTXt", "tEXt", "zTXt", "tIME":
+		return true
+	}
+	return false
+}
+
+// inspectPNG consumes chunks up to the first IDAT and returns how many bytes
+// of that IDAT chunk (data and CRC) are still unread.
+func inspectPNG(rc *recorder, strip bool) (*entity.ImageInfo, int64, error) {
+	sig, err := rc.read(len(pngMagic))
+	if err != nil {
+		return nil, 0, err
+	}
+	rc.keep(sig)
+	info := &entity.ImageInfo{Format: string(FormatPNG)}
+
+	for {
+		hdr, err := rc.read(8)
+		if err != nil {
+			return nil, 0, err
+		}
+		length := int64(binary.BigEndian.Uint32(hdr))
+		typ := string(hdr[4:])
+		if typ == "IDAT" {
+			rc.keep(hdr)
+			return info, length + 4, nil
+		}
+		if length > maxPNGHeaderChunk {
+			return nil, 0, fmt.Errorf("%w: png chunk %s too large", ErrMalformedImage, typ)
+		}
+		body, err := rc.read(int(length) + 4)
+		if err != nil {
+			return nil, 0, err
+		}
+		data := body[:length]
+
+		switch typ {
+		case "IHDR":
+			if len(data) >= 8 {
+				info.Width = int(binary.BigEndian.Uint32(data))
+				info.Height = int(binary.BigEndian.Uint32(data[4:]))
+			}
+		case "eXIf":
+			if f, err := parseExif(data); err == nil {
+				info.Orientation = f.orientation
+				info.CapturedAt = f.capturedAt
+			}
+		case "IEND":
+			rc.keep(hdr, body)
+			return info, 0, nil
+		}
+		if !(strip && isPNGMetadataChunk(typ)) {
+			rc.keep(hdr, body)
+		}
+	}
+}
+
+// pngFilter streams the chunks after the first IDAT, dropping metadata chunks
+// that are allowed to appear after the image data.
+type pngFilter struct {
+	r         io.Reader
+	remaining int64
+	pending   []byte
+}
+
+func (f *pngFilter) Read(p []byte) (int, error) {
+	for {
+		if len(f.pending) > 0 {
+			n := copy(p, f.pending)
+			f.pending = f.pending[n:]
+			return n, nil
+		}
+		if f.remaining > 0 {
+			if int64(len(p)) > f.remaining {
+				p = p[:f.remaining]
+			}
+			n, err := f.r.Read(p)
+			f.remaining -= int64(n)
+			if err == io.EOF && f.remaining > 0 {
+				err = io.ErrUnexpectedEOF
+			} else if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+
+		hdr := make([]byte, 8)
+		if _, err := io.ReadFull(f.r, hdr); err != nil {
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			return 0, fmt.Errorf("%w: %v", ErrMalformedImage, err)
+		}
+		length := int64(binary.BigEndian.Uint32(hdr)) + 4
+		if isPNGMetadataChunk(string(hdr[4:])) {
+			if _, err := io.CopyN(io.Discard, f.r, length); err != nil {
+				return 0, fmt.Errorf("%w: %v", ErrMalformedImage, err)
+			}
+			continue
+		}
+		f.pending = hdr
+		f.remaining = length
+	}
+}
@@ -0,0 +1,59 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestInspectHeaderBudget(t *testing.T) {
+	// JPEG comment segments, each small, that together exceed the budget.
+	segment := make([]byte, 4+0xFFFF-2)
+	segment[0], segment[1] = 0xFF, 0xFE
+	binary.BigEndian.PutUint16(segment[2:], 0xFFFF)
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8})
+	for jpeg.Len() <= maxHeaderBytes {
+		jpeg.Write(segment)
+	}
+	jpeg.Write([]byte{0xFF, 0xD9})
+
+	// PNG text chunks, likewise.
+	chunk := make([]byte, 8+1<<20+4)
+	binary.BigEndian.PutUint32(chunk, 1<<20)
+	copy(chunk[4:], "tEXt")
+	src := encodePNG(t, 2, 2)
+	var png bytes.Buffer
+	png.Write(src[:8+25]) // signature and IHDR
+	for png.Len() <= maxHeaderBytes {
+		png.Write(chunk)
+	}
+	png.Write(src[8+25:])
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"jpeg", jpeg.Bytes()},
+		{"png", png.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Inspect(bytes.NewReader(tt.data), true); !errors.Is(err, ErrMalformedImage) {
+				t.Errorf("strip: err = %v, want ErrMalformedImage", err)
+			}
+
+			// Without stripping the content passes through untouched.
+			r, info, err := Inspect(bytes.NewReader(tt.data), false)
+			if err != nil || info != nil {
+				t.Fatalf("info = %v, err = %v", info, err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, tt.data) {
+				t.Errorf("passthrough differs, err = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"golang.org/x/image/draw"
+	"image"
+	"io"
+)
+
+// AutoOrient decodes the image, applies the EXIF orientation to the pixels and
+// re-encodes it in its original format. Re-encoding drops all metadata; info is
+// updated to describe the upright result.
+func AutoOrient(src io.Reader, info *entity.ImageInfo, limits Limits) ([]byte, error) {
+	data, err := readLimited(src, limits.MaxSourceBytes)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if limits.MaxSourcePixels > 0 && int64(cfg.Width)*int64(cfg.Height) > limits.MaxSourcePixels {
+		return nil, fmt.Errorf("%w: source is %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	oriented := Orient(img, info.Orientation)
+	var buf bytes.Buffer
+	if err := Encode(&buf, oriented, Format(info.Format), limits.JPEGQuality); err != nil {
+		return nil, err
+	}
+	b := oriented.Bounds()
+	info.Width, info.Height, info.Orientation = b.Dx(), b.Dy(), 1
+	return buf.Bytes(), nil
+}
+
+// Orient returns img transformed so that an image carrying the given EXIF
+// orientation tag is displayed upright.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise to display
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise to display
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,210 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+type Fit string
+
+const (
+	FitContain Fit = "contain" // scale to fit inside the box, keeping aspect ratio
+	FitCover   Fit = "cover"   // scale to cover the box, cropping the overflow
+	FitFill    Fit = "fill"    // stretch to the exact box
+)
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatWebP Format = "webp"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image exceeds limits")
+	ErrInvalidOptions    = errors.New("invalid rendition options")
+)
+
+type Limits struct {
+	MaxSourceBytes  int64
+	MaxSourcePixels int64
+	MaxDimension    int
+	JPEGQuality     int
+}
+
+var DefaultLimits = Limits{
+	MaxSourceBytes:  50 << 20,
+	MaxSourcePixels: 50_000_000,
+	MaxDimension:    4096,
+	JPEGQuality:     85,
+}
+
+type Options struct {
+	Width  int
+	Height int
+	Fit    Fit
+	Format Format
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatPNG:
+		return "image/png"
+	case FormatWebP:
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
+
+// CanEncode reports whether renditions can be produced in the given format.
+// WebP sources can be decoded, but there is no pure Go WebP encoder available.
+func CanEncode(f Format) bool {
+	return f == FormatJPEG || f == FormatPNG
+}
+
+func (o Options) Validate(limits Limits) error {
+	if o.Width < 0 || o.Height < 0 || (o.Width == 0 && o.Height == 0) {
+		return fmt.Errorf("%w: width or height must be positive", ErrInvalidOptions)
+	}
+	if limits.MaxDimension > 0 && (o.Width > limits.MaxDimension || o.Height > limits.MaxDimension) {
+		return fmt.Errorf("%w: dimensions above %d", ErrInvalidOptions, limits.MaxDimension)
+	}
+	switch o.Fit {
+	case FitContain, FitCover, FitFill:
+	default:
+		return fmt.Errorf("%w: unknown fit mode %q", ErrInvalidOptions, o.Fit)
+	}
+	if !CanEncode(o.Format) {
+		return fmt.Errorf("%w: cannot encode %q", ErrUnsupportedFormat, o.Format)
+	}
+	return nil
+}
+
+// Render decodes the source image, checking its declared dimensions against
+// limits before allocating any pixels, and writes the resized rendition to w.
+func Render(src io.Reader, w io.Writer, opts Options, limits Limits) error {
+	data, err := readLimited(src, limits.MaxSourceBytes)
+	if err != nil {
+		return err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return fmt.Errorf("%w: empty image", ErrUnsupportedFormat)
+	}
+	if limits.MaxSourcePixels > 0 && int64(cfg.Width)*int64(cfg.Height) > limits.MaxSourcePixels {
+		return fmt.Errorf("%w: source is %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	// A width or height left to the aspect ratio may still be far above the
+	// limit, e.g. the height of a 1x100000 source scaled to a width of 4096.
+	width, height := boxSize(cfg.Width, cfg.Height, opts.Width, opts.Height)
+	if limits.MaxDimension > 0 && (width > limits.MaxDimension || height > limits.MaxDimension) {
+		return fmt.Errorf("%w: rendition of a %dx%d source is %dx%d", ErrTooLarge, cfg.Width, cfg.Height, width, height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	return Encode(w, Resize(img, opts.Width, opts.Height, opts.Fit), opts.Format, limits.JPEGQuality)
+}
+
+func readLimited(src io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(src)
+	}
+	data, err := io.ReadAll(io.LimitReader(src, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source image: %w", err)
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%w: source larger than %d bytes", ErrTooLarge, max)
+	}
+	return data, nil
+}
+
+// boxSize returns the box a sw x sh source is fitted into, deriving a zero
+// width or height from the source aspect ratio.
+func boxSize(sw, sh, width, height int) (int, int) {
+	if width == 0 {
+		width = scale(sw, height, sh)
+	}
+	if height == 0 {
+		height = scale(sh, width, sw)
+	}
+	return width, height
+}
+
+// scale returns v*num/den, at least 1.
+func scale(v, num, den int) int {
+	return int(max(1, int64(v)*int64(num)/int64(den)))
+}
+
+// Resize does not check the size of the rendition; Render does, before
+// decoding the source.
+func Resize(img image.Image, width, height int, fit Fit) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	width, height = boxSize(sw, sh, width, height)
+
+	srcRect := b
+	dw, dh := width, height
+	switch fit {
+	case FitContain:
+		if int64(sw)*int64(height) > int64(sh)*int64(width) {
+			dh = scale(sh, width, sw)
+		} else {
+			dw = scale(sw, height, sh)
+		}
+	case FitCover:
+		// The crop keeps at least one source pixel on extreme aspect ratios.
+		if int64(sw)*int64(height) > int64(sh)*int64(width) {
+			cw := scale(sh, width, height)
+			x0 := b.Min.X + (sw-cw)/2
+			srcRect = image.Rect(x0, b.Min.Y, x0+cw, b.Max.Y)
+		} else {
+			ch := scale(sw, height, width)
+			y0 := b.Min.Y + (sh-ch)/2
+			srcRect = image.Rect(b.Min.X, y0, b.Max.X, y0+ch)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, srcRect, draw.Src, nil)
+	return dst
+}
+
+func Encode(w io.Writer, img image.Image, format Format, jpegQuality int) error {
+	switch format {
+	case FormatJPEG:
+		if jpegQuality <= 0 {
+			jpegQuality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		return png.Encode(w, img)
+	}
+	return fmt.Errorf("%w: cannot encode %q", ErrUnsupportedFormat, format)
+}
+
+// flatten composes transparent pixels over white, JPEG has no alpha channel.
+func flatten(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,79 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderRejectsDerivedDimensionAboveLimit(t *testing.T) {
+	limits := Limits{MaxSourceBytes: 1 << 20, MaxSourcePixels: 1 << 20, MaxDimension: 4096}
+	tests := []struct {
+		name          string
+		sw, sh        int
+		width, height int
+		wantErr       error
+	}{
+		{"derived height", 1, 100_000, 4096, 0, ErrTooLarge},
+		{"derived width", 100_000, 1, 0, 4096, ErrTooLarge},
+		{"derived height within limit", 10, 20, 100, 0, nil},
+		{"derived width within limit", 20, 10, 0, 100, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Width: tt.width, Height: tt.height, Fit: FitContain, Format: FormatPNG}
+			if err := opts.Validate(limits); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			err := Render(bytes.NewReader(encodePNG(t, tt.sw, tt.sh)), io.Discard, opts, limits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Render error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResizeCoverExtremeAspectRatio(t *testing.T) {
+	tests := []struct {
+		name          string
+		sw, sh        int
+		width, height int
+	}{
+		{"wide source, tall box", 1000, 1, 10, 100},
+		{"tall source, wide box", 1, 1000, 100, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := png.Decode(bytes.NewReader(encodePNG(t, tt.sw, tt.sh)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dst := Resize(src, tt.width, tt.height, FitCover)
+			if got := dst.Bounds().Size(); got != image.Pt(tt.width, tt.height) {
+				t.Fatalf("size = %v, want %dx%d", got, tt.width, tt.height)
+			}
+			if _, _, _, a := dst.At(tt.width/2, tt.height/2).RGBA(); a == 0 {
+				t.Fatal("rendition is empty, the crop lost all source pixels")
+			}
+		})
+	}
+}
@@ -22,29 +22,2165 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type FitMode int32
+
+const (
+	FitMode_FIT_MODE_CONTAIN FitMode = 0
+	FitMode_FIT_MODE_COVER   FitMode = 1
+	FitMode_FIT_MODE_FILL    FitMode = 2
+)
+
+// Enum value maps for FitMode.
+var (
+	FitMode_name = map[int32]string{
+		0: "FIT_MODE_CONTAIN",
+		1: "FIT_MODE_COVER",
+		2: "FIT_MODE_FILL",
+	}
+	FitMode_value = map[string]int32{
+		"FIT_MODE_CONTAIN": 0,
+		"FIT_MODE_COVER":   1,
+		"FIT_MODE_FILL":    2,
+	}
+)
+
+func (x FitMode) Enum() *FitMode {
+	p := new(FitMode)
+	*p = x
+	return p
+}
+
+func (x FitMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FitMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_file_service_proto_enumTypes[0].Descriptor()
+}
+
+func (FitMode) Type() protoreflect.EnumType {
+	return &file_internal_proto_file_service_proto_enumTypes[0]
+}
+
+func (x FitMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FitMode.Descriptor instead.
+func (FitMode) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{0}
+}
+
+type ImageFormat int32
+
+const (
+	ImageFormat_IMAGE_FORMAT_JPEG ImageFormat = 0
+	ImageFormat_IMAGE_FORMAT_PNG  ImageFormat = 1
+	ImageFormat_IMAGE_FORMAT_WEBP ImageFormat = 2
+)
+
+// Enum value maps for ImageFormat.
+var (
+	ImageFormat_name = map[int32]string{
+		0: "IMAGE_FORMAT_JPEG",
+		1: "IMAGE_FORMAT_PNG",
+		2: "IMAGE_FORMAT_WEBP",
+	}
+	ImageFormat_value = map[string]int32{
+		"IMAGE_FORMAT_JPEG": 0,
+		"IMAGE_FORMAT_PNG":  1,
+		"IMAGE_FORMAT_WEBP": 2,
+	}
+)
+
+func (x ImageFormat) Enum() *ImageFormat {
+	p := new(ImageFormat)
+	*p = x
+	return p
+}
+
+func (x ImageFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_file_service_proto_enumTypes[1].Descriptor()
+}
+
+func (ImageFormat) Type() protoreflect.EnumType {
+	return &file_internal_proto_file_service_proto_enumTypes[1]
+}
+
+func (x ImageFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageFormat.Descriptor instead.
+func (ImageFormat) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{1}
+}
+
+type ArchiveFormat int32
+
+const (
+	ArchiveFormat_ARCHIVE_FORMAT_ZIP    ArchiveFormat = 0
+	ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ ArchiveFormat = 1
+)
+
+// Enum value maps for ArchiveFormat.
+var (
+	ArchiveFormat_name = map[int32]string{
+		0: "ARCHIVE_FORMAT_ZIP",
+		1: "ARCHIVE_FORMAT_TAR_GZ",
+	}
+	ArchiveFormat_value = map[string]int32{
+		"ARCHIVE_FORMAT_ZIP":    0,
+		"ARCHIVE_FORMAT_TAR_GZ": 1,
+	}
+)
+
+func (x ArchiveFormat) Enum() *ArchiveFormat {
+	p := new(ArchiveFormat)
+	*p = x
+	return p
+}
+
+func (x ArchiveFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ArchiveFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_file_service_proto_enumTypes[2].Descriptor()
+}
+
+func (ArchiveFormat) Type() protoreflect.EnumType {
+	return &file_internal_proto_file_service_proto_enumTypes[2]
+}
+
+func (x ArchiveFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ArchiveFormat.Descriptor instead.
+func (ArchiveFormat) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{2}
+}
+
+type FileEventType int32
+
+const (
+	FileEventType_FILE_EVENT_TYPE_UNSPECIFIED FileEventType = 0
+	FileEventType_FILE_EVENT_TYPE_CREATED     FileEventType = 1
+	FileEventType_FILE_EVENT_TYPE_UPDATED     FileEventType = 2
+	FileEventType_FILE_EVENT_TYPE_DELETED     FileEventType = 3
+)
+
+// Enum value maps for FileEventType.
+var (
+	FileEventType_name = map[int32]string{
+		0: "FILE_EVENT_TYPE_UNSPECIFIED",
+		1: "FILE_EVENT_TYPE_CREATED",
+		2: "FILE_EVENT_TYPE_UPDATED",
+		3: "FILE_EVENT_TYPE_DELETED",
+	}
+	FileEventType_value = map[string]int32{
+		"FILE_EVENT_TYPE_UNSPECIFIED": 0,
+		"FILE_EVENT_TYPE_CREATED":     1,
+		"FILE_EVENT_TYPE_UPDATED":     2,
+		"FILE_EVENT_TYPE_DELETED":     3,
+	}
+)
+
+func (x FileEventType) Enum() *FileEventType {
+	p := new(FileEventType)
+	*p = x
+	return p
+}
+
+func (x FileEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_file_service_proto_enumTypes[3].Descriptor()
+}
+
+func (FileEventType) Type() protoreflect.EnumType {
+	return &file_internal_proto_file_service_proto_enumTypes[3]
+}
+
+func (x FileEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileEventType.Descriptor instead.
+func (FileEventType) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{3}
+}
+
 type UploadFileRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Chunk    []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// Conditional write options, read from the first message only.
+	IfNoneMatch string `protobuf:"bytes,3,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`
+	IfMatch     string `protobuf:"bytes,4,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	// Expiration, read from the first message only; at most one may be set.
+	ExpiresAt  *timestamp.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TtlSeconds int64                `protobuf:"varint,6,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Image options for JPEG and PNG uploads, read from the first message only.
+	StripMetadata bool `protobuf:"varint,7,opt,name=strip_metadata,json=stripMetadata,proto3" json:"strip_metadata,omitempty"`
+	AutoOrient    bool `protobuf:"varint,8,opt,name=auto_orient,json=autoOrient,proto3" json:"auto_orient,omitempty"`
+	// Compression, read from the first message only: "gzip", "zstd", "none",
+	// or empty to let the server decide by content type.
+	Compression string `protobuf:"bytes,9,opt,name=compression,proto3" json:"compression,omitempty"`
+	// Treat the upload as a .zip, .tar, .tar.gz or .tgz archive (by filename)
+	// and store each entry as its own object under extract_to.
+	Extract       bool   `protobuf:"varint,10,opt,name=extract,proto3" json:"extract,omitempty"`
+	ExtractTo     string `protobuf:"bytes,11,opt,name=extract_to,json=extractTo,proto3" json:"extract_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileRequest) Reset() {
+	*x = UploadFileRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileRequest) ProtoMessage() {}
+
+func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UploadFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetIfNoneMatch() string {
+	if x != nil {
+		return x.IfNoneMatch
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetExpiresAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetStripMetadata() bool {
+	if x != nil {
+		return x.StripMetadata
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetAutoOrient() bool {
+	if x != nil {
+		return x.AutoOrient
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetExtract() bool {
+	if x != nil {
+		return x.Extract
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetExtractTo() string {
+	if x != nil {
+		return x.ExtractTo
+	}
+	return ""
+}
+
+type UploadFileResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Message   string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	VersionId string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	// Set for archive extraction only.
+	Created []*ExtractedFile `protobuf:"bytes,3,rep,name=created,proto3" json:"created,omitempty"`
+	Errors  []*ExtractError  `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	// ETag of the stored file; empty for archive extraction.
+	Etag          string `protobuf:"bytes,5,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileResponse) ProtoMessage() {}
+
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UploadFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetCreated() []*ExtractedFile {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *UploadFileResponse) GetErrors() []*ExtractError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *UploadFileResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type ExtractedFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractedFile) Reset() {
+	*x = ExtractedFile{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractedFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractedFile) ProtoMessage() {}
+
+func (x *ExtractedFile) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractedFile.ProtoReflect.Descriptor instead.
+func (*ExtractedFile) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExtractedFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExtractedFile) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *ExtractedFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ExtractError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         string                 `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractError) Reset() {
+	*x = ExtractError{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractError) ProtoMessage() {}
+
+func (x *ExtractError) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractError.ProtoReflect.Descriptor instead.
+func (*ExtractError) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExtractError) GetEntry() string {
+	if x != nil {
+		return x.Entry
+	}
+	return ""
+}
+
+func (x *ExtractError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DownloadFileRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Filename  string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Offset    int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length    int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	VersionId string                 `protobuf:"bytes,4,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	// Return the stored bytes as-is instead of decompressing them.
+	RawEncoding bool `protobuf:"varint,5,opt,name=raw_encoding,json=rawEncoding,proto3" json:"raw_encoding,omitempty"`
+	// Preferred size of each response chunk in bytes; 0 uses the server
+	// default. The server clamps it to its configured bounds.
+	ChunkSize int32 `protobuf:"varint,6,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// Fail with FAILED_PRECONDITION unless the file has this ETag, so that
+	// the ranges of one file read by several calls belong to one version.
+	IfMatch       string `protobuf:"bytes,7,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileRequest) ProtoMessage() {}
+
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DownloadFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetRawEncoding() bool {
+	if x != nil {
+		return x.RawEncoding
+	}
+	return false
+}
+
+func (x *DownloadFileRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type DownloadFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileResponse) ProtoMessage() {}
+
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DownloadFileResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type ListFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{6}
+}
+
+type FileMetadata struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt *timestamp.Timestamp   `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ExpiresAt *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Size      int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Etag      string                 `protobuf:"bytes,6,opt,name=etag,proto3" json:"etag,omitempty"`
+	ImageInfo *ImageInfo             `protobuf:"bytes,7,opt,name=image_info,json=imageInfo,proto3" json:"image_info,omitempty"`
+	// Size of the object as stored, after compression; size is the logical size.
+	StoredSize  int64  `protobuf:"varint,8,opt,name=stored_size,json=storedSize,proto3" json:"stored_size,omitempty"`
+	Compression string `protobuf:"bytes,9,opt,name=compression,proto3" json:"compression,omitempty"`
+	// SHA-256 of the content, set when the file is stored deduplicated.
+	Digest string `protobuf:"bytes,10,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Client-defined attributes, see SetAttributesOp.
+	Attributes    map[string]string `protobuf:"bytes,11,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileMetadata) Reset() {
+	*x = FileMetadata{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileMetadata) ProtoMessage() {}
+
+func (x *FileMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileMetadata.ProtoReflect.Descriptor instead.
+func (*FileMetadata) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FileMetadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetCreatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *FileMetadata) GetUpdatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *FileMetadata) GetExpiresAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *FileMetadata) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileMetadata) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetImageInfo() *ImageInfo {
+	if x != nil {
+		return x.ImageInfo
+	}
+	return nil
+}
+
+func (x *FileMetadata) GetStoredSize() int64 {
+	if x != nil {
+		return x.StoredSize
+	}
+	return 0
+}
+
+func (x *FileMetadata) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type ImageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Width         int32                  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Orientation   int32                  `protobuf:"varint,4,opt,name=orientation,proto3" json:"orientation,omitempty"`
+	CapturedAt    *timestamp.Timestamp   `protobuf:"bytes,5,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageInfo) Reset() {
+	*x = ImageInfo{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageInfo) ProtoMessage() {}
+
+func (x *ImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ImageInfo) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImageInfo) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetOrientation() int32 {
+	if x != nil {
+		return x.Orientation
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetCapturedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.CapturedAt
+	}
+	return nil
+}
+
+type ListFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileMetadata        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesResponse) Reset() {
+	*x = ListFilesResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesResponse) ProtoMessage() {}
+
+func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListFilesResponse) GetFiles() []*FileMetadata {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileVersion struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	VersionId      string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Size           int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Etag           string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	IsLatest       bool                   `protobuf:"varint,5,opt,name=is_latest,json=isLatest,proto3" json:"is_latest,omitempty"`
+	IsDeleteMarker bool                   `protobuf:"varint,6,opt,name=is_delete_marker,json=isDeleteMarker,proto3" json:"is_delete_marker,omitempty"`
+	UpdatedAt      *timestamp.Timestamp   `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FileVersion) Reset() {
+	*x = FileVersion{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileVersion) ProtoMessage() {}
+
+func (x *FileVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileVersion.ProtoReflect.Descriptor instead.
+func (*FileVersion) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FileVersion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileVersion) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *FileVersion) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileVersion) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *FileVersion) GetIsLatest() bool {
+	if x != nil {
+		return x.IsLatest
+	}
+	return false
+}
+
+func (x *FileVersion) GetIsDeleteMarker() bool {
+	if x != nil {
+		return x.IsDeleteMarker
+	}
+	return false
+}
+
+func (x *FileVersion) GetUpdatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListFileVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFileVersionsRequest) Reset() {
+	*x = ListFileVersionsRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFileVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFileVersionsRequest) ProtoMessage() {}
+
+func (x *ListFileVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFileVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListFileVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListFileVersionsRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type ListFileVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*FileVersion         `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFileVersionsResponse) Reset() {
+	*x = ListFileVersionsResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFileVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFileVersionsResponse) ProtoMessage() {}
+
+func (x *ListFileVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFileVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListFileVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListFileVersionsResponse) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type RestoreFileVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFileVersionRequest) Reset() {
+	*x = RestoreFileVersionRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFileVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFileVersionRequest) ProtoMessage() {}
+
+func (x *RestoreFileVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFileVersionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreFileVersionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RestoreFileVersionRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *RestoreFileVersionRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type RestoreFileVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VersionId     string                 `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFileVersionResponse) Reset() {
+	*x = RestoreFileVersionResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFileVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFileVersionResponse) ProtoMessage() {}
+
+func (x *RestoreFileVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFileVersionResponse.ProtoReflect.Descriptor instead.
+func (*RestoreFileVersionResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestoreFileVersionResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type CopyFileRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Source      string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Replace an existing destination instead of failing with ALREADY_EXISTS.
+	Overwrite     bool `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyFileRequest) Reset() {
+	*x = CopyFileRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFileRequest) ProtoMessage() {}
+
+func (x *CopyFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFileRequest.ProtoReflect.Descriptor instead.
+func (*CopyFileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CopyFileRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CopyFileRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *CopyFileRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type CopyFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VersionId     string                 `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyFileResponse) Reset() {
+	*x = CopyFileResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFileResponse) ProtoMessage() {}
+
+func (x *CopyFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFileResponse.ProtoReflect.Descriptor instead.
+func (*CopyFileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CopyFileResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type MoveFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveFileRequest) Reset() {
+	*x = MoveFileRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveFileRequest) ProtoMessage() {}
+
+func (x *MoveFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveFileRequest.ProtoReflect.Descriptor instead.
+func (*MoveFileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MoveFileRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *MoveFileRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *MoveFileRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type MoveFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VersionId     string                 `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveFileResponse) Reset() {
+	*x = MoveFileResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveFileResponse) ProtoMessage() {}
+
+func (x *MoveFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveFileResponse.ProtoReflect.Descriptor instead.
+func (*MoveFileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *MoveFileResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type DeleteFileRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Fail with FAILED_PRECONDITION unless the file has this ETag.
+	IfMatch       string `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+type DeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrashId       string                 `protobuf:"bytes,1,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileResponse) Reset() {
+	*x = DeleteFileResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileResponse) ProtoMessage() {}
+
+func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteFileResponse) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+type TrashItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrashId       string                 `protobuf:"bytes,1,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"`
+	OriginalName  string                 `protobuf:"bytes,2,opt,name=original_name,json=originalName,proto3" json:"original_name,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	DeletedAt     *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashItem) Reset() {
+	*x = TrashItem{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashItem) ProtoMessage() {}
+
+func (x *TrashItem) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashItem.ProtoReflect.Descriptor instead.
+func (*TrashItem) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TrashItem) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+func (x *TrashItem) GetOriginalName() string {
+	if x != nil {
+		return x.OriginalName
+	}
+	return ""
+}
+
+func (x *TrashItem) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *TrashItem) GetDeletedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{22}
+}
+
+type ListTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*TrashItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashResponse) ProtoMessage() {}
+
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListTrashResponse) GetItems() []*TrashItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type RestoreFromTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrashId       string                 `protobuf:"bytes,1,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,2,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFromTrashRequest) Reset() {
+	*x = RestoreFromTrashRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFromTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFromTrashRequest) ProtoMessage() {}
+
+func (x *RestoreFromTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFromTrashRequest.ProtoReflect.Descriptor instead.
+func (*RestoreFromTrashRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RestoreFromTrashRequest) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+func (x *RestoreFromTrashRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type RestoreFromTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFromTrashResponse) Reset() {
+	*x = RestoreFromTrashResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFromTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFromTrashResponse) ProtoMessage() {}
+
+func (x *RestoreFromTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFromTrashResponse.ProtoReflect.Descriptor instead.
+func (*RestoreFromTrashResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RestoreFromTrashResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *RestoreFromTrashResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type EmptyTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmptyTrashRequest) Reset() {
+	*x = EmptyTrashRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyTrashRequest) ProtoMessage() {}
+
+func (x *EmptyTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyTrashRequest.ProtoReflect.Descriptor instead.
+func (*EmptyTrashRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{26}
+}
+
+type EmptyTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purged        int64                  `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmptyTrashResponse) Reset() {
+	*x = EmptyTrashResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyTrashResponse) ProtoMessage() {}
+
+func (x *EmptyTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyTrashResponse.ProtoReflect.Descriptor instead.
+func (*EmptyTrashResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *EmptyTrashResponse) GetPurged() int64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type GetImageRenditionRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Either dimension may be zero to keep the source aspect ratio.
+	Width         int32       `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32       `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Fit           FitMode     `protobuf:"varint,4,opt,name=fit,proto3,enum=file_service.FitMode" json:"fit,omitempty"`
+	Format        ImageFormat `protobuf:"varint,5,opt,name=format,proto3,enum=file_service.ImageFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImageRenditionRequest) Reset() {
+	*x = GetImageRenditionRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImageRenditionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageRenditionRequest) ProtoMessage() {}
+
+func (x *GetImageRenditionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageRenditionRequest.ProtoReflect.Descriptor instead.
+func (*GetImageRenditionRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetImageRenditionRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *GetImageRenditionRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *GetImageRenditionRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *GetImageRenditionRequest) GetFit() FitMode {
+	if x != nil {
+		return x.Fit
+	}
+	return FitMode_FIT_MODE_CONTAIN
+}
+
+func (x *GetImageRenditionRequest) GetFormat() ImageFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ImageFormat_IMAGE_FORMAT_JPEG
+}
+
+type GetImageRenditionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Chunk []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// Set on the first message only.
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImageRenditionResponse) Reset() {
+	*x = GetImageRenditionResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImageRenditionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageRenditionResponse) ProtoMessage() {}
+
+func (x *GetImageRenditionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageRenditionResponse.ProtoReflect.Descriptor instead.
+func (*GetImageRenditionResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetImageRenditionResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *GetImageRenditionResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type DownloadArchiveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Either an explicit list of files or a name prefix, not both.
+	Filenames     []string      `protobuf:"bytes,1,rep,name=filenames,proto3" json:"filenames,omitempty"`
+	Prefix        string        `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Format        ArchiveFormat `protobuf:"varint,3,opt,name=format,proto3,enum=file_service.ArchiveFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadArchiveRequest) Reset() {
+	*x = DownloadArchiveRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadArchiveRequest) ProtoMessage() {}
+
+func (x *DownloadArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadArchiveRequest.ProtoReflect.Descriptor instead.
+func (*DownloadArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DownloadArchiveRequest) GetFilenames() []string {
+	if x != nil {
+		return x.Filenames
+	}
+	return nil
+}
+
+func (x *DownloadArchiveRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *DownloadArchiveRequest) GetFormat() ArchiveFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ArchiveFormat_ARCHIVE_FORMAT_ZIP
+}
+
+type DownloadArchiveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadArchiveResponse) Reset() {
+	*x = DownloadArchiveResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadArchiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadArchiveResponse) ProtoMessage() {}
+
+func (x *DownloadArchiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadArchiveResponse.ProtoReflect.Descriptor instead.
+func (*DownloadArchiveResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DownloadArchiveResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type StatOp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	Chunk         []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileRequest) Reset() {
-	*x = UploadFileRequest{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[0]
+func (x *StatOp) Reset() {
+	*x = StatOp{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatOp) ProtoMessage() {}
+
+func (x *StatOp) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatOp.ProtoReflect.Descriptor instead.
+func (*StatOp) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *StatOp) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type SetAttributesOp struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Keys are lower-case letters, digits and dashes; an empty value removes
+	// the attribute.
+	Attributes    map[string]string `protobuf:"bytes,2,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAttributesOp) Reset() {
+	*x = SetAttributesOp{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileRequest) String() string {
+func (x *SetAttributesOp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileRequest) ProtoMessage() {}
+func (*SetAttributesOp) ProtoMessage() {}
 
-func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[0]
+func (x *SetAttributesOp) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,47 +2191,55 @@ func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
-func (*UploadFileRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use SetAttributesOp.ProtoReflect.Descriptor instead.
+func (*SetAttributesOp) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *UploadFileRequest) GetFilename() string {
+func (x *SetAttributesOp) GetFilename() string {
 	if x != nil {
 		return x.Filename
 	}
 	return ""
 }
 
-func (x *UploadFileRequest) GetChunk() []byte {
+func (x *SetAttributesOp) GetAttributes() map[string]string {
 	if x != nil {
-		return x.Chunk
+		return x.Attributes
 	}
 	return nil
 }
 
-type UploadFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+type BatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Echoed back in the matching response; results arrive in completion order.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Op:
+	//
+	//	*BatchRequest_Stat
+	//	*BatchRequest_Delete
+	//	*BatchRequest_Copy
+	//	*BatchRequest_SetAttributes
+	Op            isBatchRequest_Op `protobuf_oneof:"op"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileResponse) Reset() {
-	*x = UploadFileResponse{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[1]
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileResponse) String() string {
+func (x *BatchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileResponse) ProtoMessage() {}
+func (*BatchRequest) ProtoMessage() {}
 
-func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[1]
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -106,40 +2250,121 @@ func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
-func (*UploadFileResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *UploadFileResponse) GetMessage() string {
+func (x *BatchRequest) GetId() string {
 	if x != nil {
-		return x.Message
+		return x.Id
 	}
 	return ""
 }
 
-type DownloadFileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+func (x *BatchRequest) GetOp() isBatchRequest_Op {
+	if x != nil {
+		return x.Op
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetStat() *StatOp {
+	if x != nil {
+		if x, ok := x.Op.(*BatchRequest_Stat); ok {
+			return x.Stat
+		}
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetDelete() *DeleteFileRequest {
+	if x != nil {
+		if x, ok := x.Op.(*BatchRequest_Delete); ok {
+			return x.Delete
+		}
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetCopy() *CopyFileRequest {
+	if x != nil {
+		if x, ok := x.Op.(*BatchRequest_Copy); ok {
+			return x.Copy
+		}
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetSetAttributes() *SetAttributesOp {
+	if x != nil {
+		if x, ok := x.Op.(*BatchRequest_SetAttributes); ok {
+			return x.SetAttributes
+		}
+	}
+	return nil
+}
+
+type isBatchRequest_Op interface {
+	isBatchRequest_Op()
+}
+
+type BatchRequest_Stat struct {
+	Stat *StatOp `protobuf:"bytes,2,opt,name=stat,proto3,oneof"`
+}
+
+type BatchRequest_Delete struct {
+	Delete *DeleteFileRequest `protobuf:"bytes,3,opt,name=delete,proto3,oneof"`
+}
+
+type BatchRequest_Copy struct {
+	Copy *CopyFileRequest `protobuf:"bytes,4,opt,name=copy,proto3,oneof"`
+}
+
+type BatchRequest_SetAttributes struct {
+	SetAttributes *SetAttributesOp `protobuf:"bytes,5,opt,name=set_attributes,json=setAttributes,proto3,oneof"`
+}
+
+func (*BatchRequest_Stat) isBatchRequest_Op() {}
+
+func (*BatchRequest_Delete) isBatchRequest_Op() {}
+
+func (*BatchRequest_Copy) isBatchRequest_Op() {}
+
+func (*BatchRequest_SetAttributes) isBatchRequest_Op() {}
+
+type BatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// A google.rpc.Code; OK when the operation succeeded.
+	Code  int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*BatchResponse_Stat
+	//	*BatchResponse_Delete
+	//	*BatchResponse_Copy
+	//	*BatchResponse_SetAttributes
+	Result        isBatchResponse_Result `protobuf_oneof:"result"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadFileRequest) Reset() {
-	*x = DownloadFileRequest{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[2]
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadFileRequest) String() string {
+func (x *BatchResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileRequest) ProtoMessage() {}
+func (*BatchResponse) ProtoMessage() {}
 
-func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[2]
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -150,83 +2375,128 @@ func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
-func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *DownloadFileRequest) GetFilename() string {
+func (x *BatchResponse) GetId() string {
 	if x != nil {
-		return x.Filename
+		return x.Id
 	}
 	return ""
 }
 
-type DownloadFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *BatchResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
 }
 
-func (x *DownloadFileResponse) Reset() {
-	*x = DownloadFileResponse{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[3]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *BatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-func (x *DownloadFileResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *BatchResponse) GetResult() isBatchResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
 }
 
-func (*DownloadFileResponse) ProtoMessage() {}
+func (x *BatchResponse) GetStat() *FileMetadata {
+	if x != nil {
+		if x, ok := x.Result.(*BatchResponse_Stat); ok {
+			return x.Stat
+		}
+	}
+	return nil
+}
 
-func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[3]
+func (x *BatchResponse) GetDelete() *DeleteFileResponse {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		if x, ok := x.Result.(*BatchResponse_Delete); ok {
+			return x.Delete
 		}
-		return ms
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
-func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{3}
+func (x *BatchResponse) GetCopy() *CopyFileResponse {
+	if x != nil {
+		if x, ok := x.Result.(*BatchResponse_Copy); ok {
+			return x.Copy
+		}
+	}
+	return nil
 }
 
-func (x *DownloadFileResponse) GetChunk() []byte {
+func (x *BatchResponse) GetSetAttributes() *FileMetadata {
 	if x != nil {
-		return x.Chunk
+		if x, ok := x.Result.(*BatchResponse_SetAttributes); ok {
+			return x.SetAttributes
+		}
 	}
 	return nil
 }
 
-type ListFilesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type isBatchResponse_Result interface {
+	isBatchResponse_Result()
+}
+
+type BatchResponse_Stat struct {
+	Stat *FileMetadata `protobuf:"bytes,4,opt,name=stat,proto3,oneof"`
+}
+
+type BatchResponse_Delete struct {
+	Delete *DeleteFileResponse `protobuf:"bytes,5,opt,name=delete,proto3,oneof"`
+}
+
+type BatchResponse_Copy struct {
+	Copy *CopyFileResponse `protobuf:"bytes,6,opt,name=copy,proto3,oneof"`
+}
+
+type BatchResponse_SetAttributes struct {
+	SetAttributes *FileMetadata `protobuf:"bytes,7,opt,name=set_attributes,json=setAttributes,proto3,oneof"`
+}
+
+func (*BatchResponse_Stat) isBatchResponse_Result() {}
+
+func (*BatchResponse_Delete) isBatchResponse_Result() {}
+
+func (*BatchResponse_Copy) isBatchResponse_Result() {}
+
+func (*BatchResponse_SetAttributes) isBatchResponse_Result() {}
+
+type WatchFilesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Prefix string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Resume after this event; empty to receive new events only. An expired
+	// cursor fails with OUT_OF_RANGE and the client should re-list.
+	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesRequest) Reset() {
-	*x = ListFilesRequest{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[4]
+func (x *WatchFilesRequest) Reset() {
+	*x = WatchFilesRequest{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesRequest) String() string {
+func (x *WatchFilesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesRequest) ProtoMessage() {}
+func (*WatchFilesRequest) ProtoMessage() {}
 
-func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[4]
+func (x *WatchFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -237,35 +2507,53 @@ func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
-func (*ListFilesRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use WatchFilesRequest.ProtoReflect.Descriptor instead.
+func (*WatchFilesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{36}
 }
 
-type FileMetadata struct {
+func (x *WatchFilesRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *WatchFilesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type FileEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	CreatedAt     *timestamp.Timestamp   `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Type          FileEventType          `protobuf:"varint,2,opt,name=type,proto3,enum=file_service.FileEventType" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Etag          string                 `protobuf:"bytes,5,opt,name=etag,proto3" json:"etag,omitempty"`
+	VersionId     string                 `protobuf:"bytes,6,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Time          *timestamp.Timestamp   `protobuf:"bytes,7,opt,name=time,proto3" json:"time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileMetadata) Reset() {
-	*x = FileMetadata{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[5]
+func (x *FileEvent) Reset() {
+	*x = FileEvent{}
+	mi := &file_internal_proto_file_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileMetadata) String() string {
+func (x *FileEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileMetadata) ProtoMessage() {}
+func (*FileEvent) ProtoMessage() {}
 
-func (x *FileMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[5]
+func (x *FileEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_file_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,72 +2564,56 @@ func (x *FileMetadata) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileMetadata.ProtoReflect.Descriptor instead.
-func (*FileMetadata) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use FileEvent.ProtoReflect.Descriptor instead.
+func (*FileEvent) Descriptor() ([]byte, []int) {
+	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *FileMetadata) GetName() string {
+func (x *FileEvent) GetCursor() string {
 	if x != nil {
-		return x.Name
+		return x.Cursor
 	}
 	return ""
 }
 
-func (x *FileMetadata) GetCreatedAt() *timestamp.Timestamp {
+func (x *FileEvent) GetType() FileEventType {
 	if x != nil {
-		return x.CreatedAt
+		return x.Type
 	}
-	return nil
+	return FileEventType_FILE_EVENT_TYPE_UNSPECIFIED
 }
 
-func (x *FileMetadata) GetUpdatedAt() *timestamp.Timestamp {
+func (x *FileEvent) GetName() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.Name
 	}
-	return nil
-}
-
-type ListFilesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*FileMetadata        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ListFilesResponse) Reset() {
-	*x = ListFilesResponse{}
-	mi := &file_internal_proto_file_service_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+	return ""
 }
 
-func (x *ListFilesResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *FileEvent) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
 }
 
-func (*ListFilesResponse) ProtoMessage() {}
-
-func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_file_service_proto_msgTypes[6]
+func (x *FileEvent) GetEtag() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Etag
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
-func (*ListFilesResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_file_service_proto_rawDescGZIP(), []int{6}
+func (x *FileEvent) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
 }
 
-func (x *ListFilesResponse) GetFiles() []*FileMetadata {
+func (x *FileEvent) GetTime() *timestamp.Timestamp {
 	if x != nil {
-		return x.Files
+		return x.Time
 	}
 	return nil
 }
@@ -350,30 +2622,233 @@ var File_internal_proto_file_service_proto protoreflect.FileDescriptor
 
 const file_internal_proto_file_service_proto_rawDesc = "" +
 	"\n" +
-	"!internal/proto/file_service.proto\x12\ffile_service\x1a\x1fgoogle/protobuf/timestamp.proto\"E\n" +
+	"!internal/proto/file_service.proto\x12\ffile_service\x1a\x1fgoogle/protobuf/timestamp.proto\"\x83\x03\n" +
 	"\x11UploadFileRequest\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x14\n" +
-	"\x05chunk\x18\x02 \x01(\fR\x05chunk\".\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk\x12\"\n" +
+	"\rif_none_match\x18\x03 \x01(\tR\vifNoneMatch\x12\x19\n" +
+	"\bif_match\x18\x04 \x01(\tR\aifMatch\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1f\n" +
+	"\vttl_seconds\x18\x06 \x01(\x03R\n" +
+	"ttlSeconds\x12%\n" +
+	"\x0estrip_metadata\x18\a \x01(\bR\rstripMetadata\x12\x1f\n" +
+	"\vauto_orient\x18\b \x01(\bR\n" +
+	"autoOrient\x12 \n" +
+	"\vcompression\x18\t \x01(\tR\vcompression\x12\x18\n" +
+	"\aextract\x18\n" +
+	" \x01(\bR\aextract\x12\x1d\n" +
+	"\n" +
+	"extract_to\x18\v \x01(\tR\textractTo\"\xcc\x01\n" +
 	"\x12UploadFileResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"1\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x125\n" +
+	"\acreated\x18\x03 \x03(\v2\x1b.file_service.ExtractedFileR\acreated\x122\n" +
+	"\x06errors\x18\x04 \x03(\v2\x1a.file_service.ExtractErrorR\x06errors\x12\x12\n" +
+	"\x04etag\x18\x05 \x01(\tR\x04etag\"V\n" +
+	"\rExtractedFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\":\n" +
+	"\fExtractError\x12\x14\n" +
+	"\x05entry\x18\x01 \x01(\tR\x05entry\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xdd\x01\n" +
 	"\x13DownloadFileRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\",\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x04 \x01(\tR\tversionId\x12!\n" +
+	"\fraw_encoding\x18\x05 \x01(\bR\vrawEncoding\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x06 \x01(\x05R\tchunkSize\x12\x19\n" +
+	"\bif_match\x18\a \x01(\tR\aifMatch\",\n" +
 	"\x14DownloadFileResponse\x12\x14\n" +
 	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"\x12\n" +
-	"\x10ListFilesRequest\"\x98\x01\n" +
+	"\x10ListFilesRequest\"\x99\x04\n" +
 	"\fFileMetadata\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x129\n" +
 	"\n" +
 	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"E\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x06 \x01(\tR\x04etag\x126\n" +
+	"\n" +
+	"image_info\x18\a \x01(\v2\x17.file_service.ImageInfoR\timageInfo\x12\x1f\n" +
+	"\vstored_size\x18\b \x01(\x03R\n" +
+	"storedSize\x12 \n" +
+	"\vcompression\x18\t \x01(\tR\vcompression\x12\x16\n" +
+	"\x06digest\x18\n" +
+	" \x01(\tR\x06digest\x12J\n" +
+	"\n" +
+	"attributes\x18\v \x03(\v2*.file_service.FileMetadata.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb0\x01\n" +
+	"\tImageInfo\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12 \n" +
+	"\vorientation\x18\x04 \x01(\x05R\vorientation\x12;\n" +
+	"\vcaptured_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"capturedAt\"E\n" +
 	"\x11ListFilesResponse\x120\n" +
-	"\x05files\x18\x01 \x03(\v2\x1a.file_service.FileMetadataR\x05files2\x87\x02\n" +
+	"\x05files\x18\x01 \x03(\v2\x1a.file_service.FileMetadataR\x05files\"\xea\x01\n" +
+	"\vFileVersion\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x04 \x01(\tR\x04etag\x12\x1b\n" +
+	"\tis_latest\x18\x05 \x01(\bR\bisLatest\x12(\n" +
+	"\x10is_delete_marker\x18\x06 \x01(\bR\x0eisDeleteMarker\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"5\n" +
+	"\x17ListFileVersionsRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"Q\n" +
+	"\x18ListFileVersionsResponse\x125\n" +
+	"\bversions\x18\x01 \x03(\v2\x19.file_service.FileVersionR\bversions\"V\n" +
+	"\x19RestoreFileVersionRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\";\n" +
+	"\x1aRestoreFileVersionResponse\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x01 \x01(\tR\tversionId\"i\n" +
+	"\x0fCopyFileRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"1\n" +
+	"\x10CopyFileResponse\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x01 \x01(\tR\tversionId\"i\n" +
+	"\x0fMoveFileRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"1\n" +
+	"\x10MoveFileResponse\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x01 \x01(\tR\tversionId\"J\n" +
+	"\x11DeleteFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatch\"/\n" +
+	"\x12DeleteFileResponse\x12\x19\n" +
+	"\btrash_id\x18\x01 \x01(\tR\atrashId\"\x9a\x01\n" +
+	"\tTrashItem\x12\x19\n" +
+	"\btrash_id\x18\x01 \x01(\tR\atrashId\x12#\n" +
+	"\roriginal_name\x18\x02 \x01(\tR\foriginalName\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x129\n" +
+	"\n" +
+	"deleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\"\x12\n" +
+	"\x10ListTrashRequest\"B\n" +
+	"\x11ListTrashResponse\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.file_service.TrashItemR\x05items\"R\n" +
+	"\x17RestoreFromTrashRequest\x12\x19\n" +
+	"\btrash_id\x18\x01 \x01(\tR\atrashId\x12\x1c\n" +
+	"\toverwrite\x18\x02 \x01(\bR\toverwrite\"U\n" +
+	"\x18RestoreFromTrashResponse\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\"\x13\n" +
+	"\x11EmptyTrashRequest\",\n" +
+	"\x12EmptyTrashResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x03R\x06purged\"\xc0\x01\n" +
+	"\x18GetImageRenditionRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12'\n" +
+	"\x03fit\x18\x04 \x01(\x0e2\x15.file_service.FitModeR\x03fit\x121\n" +
+	"\x06format\x18\x05 \x01(\x0e2\x19.file_service.ImageFormatR\x06format\"T\n" +
+	"\x19GetImageRenditionResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"\x83\x01\n" +
+	"\x16DownloadArchiveRequest\x12\x1c\n" +
+	"\tfilenames\x18\x01 \x03(\tR\tfilenames\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x123\n" +
+	"\x06format\x18\x03 \x01(\x0e2\x1b.file_service.ArchiveFormatR\x06format\"/\n" +
+	"\x17DownloadArchiveResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"$\n" +
+	"\x06StatOp\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"\xbb\x01\n" +
+	"\x0fSetAttributesOp\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12M\n" +
+	"\n" +
+	"attributes\x18\x02 \x03(\v2-.file_service.SetAttributesOp.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x88\x02\n" +
+	"\fBatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x04stat\x18\x02 \x01(\v2\x14.file_service.StatOpH\x00R\x04stat\x129\n" +
+	"\x06delete\x18\x03 \x01(\v2\x1f.file_service.DeleteFileRequestH\x00R\x06delete\x123\n" +
+	"\x04copy\x18\x04 \x01(\v2\x1d.file_service.CopyFileRequestH\x00R\x04copy\x12F\n" +
+	"\x0eset_attributes\x18\x05 \x01(\v2\x1d.file_service.SetAttributesOpH\x00R\rsetAttributesB\x04\n" +
+	"\x02op\"\xbc\x02\n" +
+	"\rBatchResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x120\n" +
+	"\x04stat\x18\x04 \x01(\v2\x1a.file_service.FileMetadataH\x00R\x04stat\x12:\n" +
+	"\x06delete\x18\x05 \x01(\v2 .file_service.DeleteFileResponseH\x00R\x06delete\x124\n" +
+	"\x04copy\x18\x06 \x01(\v2\x1e.file_service.CopyFileResponseH\x00R\x04copy\x12C\n" +
+	"\x0eset_attributes\x18\a \x01(\v2\x1a.file_service.FileMetadataH\x00R\rsetAttributesB\b\n" +
+	"\x06result\"C\n" +
+	"\x11WatchFilesRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\xdf\x01\n" +
+	"\tFileEvent\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12/\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1b.file_service.FileEventTypeR\x04type\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x05 \x01(\tR\x04etag\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x06 \x01(\tR\tversionId\x12.\n" +
+	"\x04time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x04time*F\n" +
+	"\aFitMode\x12\x14\n" +
+	"\x10FIT_MODE_CONTAIN\x10\x00\x12\x12\n" +
+	"\x0eFIT_MODE_COVER\x10\x01\x12\x11\n" +
+	"\rFIT_MODE_FILL\x10\x02*Q\n" +
+	"\vImageFormat\x12\x15\n" +
+	"\x11IMAGE_FORMAT_JPEG\x10\x00\x12\x14\n" +
+	"\x10IMAGE_FORMAT_PNG\x10\x01\x12\x15\n" +
+	"\x11IMAGE_FORMAT_WEBP\x10\x02*B\n" +
+	"\rArchiveFormat\x12\x16\n" +
+	"\x12ARCHIVE_FORMAT_ZIP\x10\x00\x12\x19\n" +
+	"\x15ARCHIVE_FORMAT_TAR_GZ\x10\x01*\x87\x01\n" +
+	"\rFileEventType\x12\x1f\n" +
+	"\x1bFILE_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17FILE_EVENT_TYPE_CREATED\x10\x01\x12\x1b\n" +
+	"\x17FILE_EVENT_TYPE_UPDATED\x10\x02\x12\x1b\n" +
+	"\x17FILE_EVENT_TYPE_DELETED\x10\x032\x96\n" +
+	"\n" +
 	"\vFileService\x12Q\n" +
 	"\n" +
 	"UploadFile\x12\x1f.file_service.UploadFileRequest\x1a .file_service.UploadFileResponse(\x01\x12W\n" +
 	"\fDownloadFile\x12!.file_service.DownloadFileRequest\x1a\".file_service.DownloadFileResponse0\x01\x12L\n" +
-	"\tListFiles\x12\x1e.file_service.ListFilesRequest\x1a\x1f.file_service.ListFilesResponseB>Z<github.com/PianyCoder/test_file_service/internal/proto;protob\x06proto3"
+	"\tListFiles\x12\x1e.file_service.ListFilesRequest\x1a\x1f.file_service.ListFilesResponse\x12a\n" +
+	"\x10ListFileVersions\x12%.file_service.ListFileVersionsRequest\x1a&.file_service.ListFileVersionsResponse\x12g\n" +
+	"\x12RestoreFileVersion\x12'.file_service.RestoreFileVersionRequest\x1a(.file_service.RestoreFileVersionResponse\x12I\n" +
+	"\bCopyFile\x12\x1d.file_service.CopyFileRequest\x1a\x1e.file_service.CopyFileResponse\x12I\n" +
+	"\bMoveFile\x12\x1d.file_service.MoveFileRequest\x1a\x1e.file_service.MoveFileResponse\x12O\n" +
+	"\n" +
+	"DeleteFile\x12\x1f.file_service.DeleteFileRequest\x1a .file_service.DeleteFileResponse\x12L\n" +
+	"\tListTrash\x12\x1e.file_service.ListTrashRequest\x1a\x1f.file_service.ListTrashResponse\x12a\n" +
+	"\x10RestoreFromTrash\x12%.file_service.RestoreFromTrashRequest\x1a&.file_service.RestoreFromTrashResponse\x12O\n" +
+	"\n" +
+	"EmptyTrash\x12\x1f.file_service.EmptyTrashRequest\x1a .file_service.EmptyTrashResponse\x12f\n" +
+	"\x11GetImageRendition\x12&.file_service.GetImageRenditionRequest\x1a'.file_service.GetImageRenditionResponse0\x01\x12`\n" +
+	"\x0fDownloadArchive\x12$.file_service.DownloadArchiveRequest\x1a%.file_service.DownloadArchiveResponse0\x01\x12D\n" +
+	"\x05Batch\x12\x1a.file_service.BatchRequest\x1a\x1b.file_service.BatchResponse(\x010\x01\x12H\n" +
+	"\n" +
+	"WatchFiles\x12\x1f.file_service.WatchFilesRequest\x1a\x17.file_service.FileEvent0\x01B>Z<github.com/PianyCoder/test_file_service/internal/proto;protob\x06proto3"
 
 var (
 	file_internal_proto_file_service_proto_rawDescOnce sync.Once
@@ -387,32 +2862,119 @@ func file_internal_proto_file_service_proto_rawDescGZIP() []byte {
 	return file_internal_proto_file_service_proto_rawDescData
 }
 
-var file_internal_proto_file_service_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_internal_proto_file_service_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_internal_proto_file_service_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_internal_proto_file_service_proto_goTypes = []any{
-	(*UploadFileRequest)(nil),    // 0: file_service.UploadFileRequest
-	(*UploadFileResponse)(nil),   // 1: file_service.UploadFileResponse
-	(*DownloadFileRequest)(nil),  // 2: file_service.DownloadFileRequest
-	(*DownloadFileResponse)(nil), // 3: file_service.DownloadFileResponse
-	(*ListFilesRequest)(nil),     // 4: file_service.ListFilesRequest
-	(*FileMetadata)(nil),         // 5: file_service.FileMetadata
-	(*ListFilesResponse)(nil),    // 6: file_service.ListFilesResponse
-	(*timestamp.Timestamp)(nil),  // 7: google.protobuf.Timestamp
+	(FitMode)(0),                       // 0: file_service.FitMode
+	(ImageFormat)(0),                   // 1: file_service.ImageFormat
+	(ArchiveFormat)(0),                 // 2: file_service.ArchiveFormat
+	(FileEventType)(0),                 // 3: file_service.FileEventType
+	(*UploadFileRequest)(nil),          // 4: file_service.UploadFileRequest
+	(*UploadFileResponse)(nil),         // 5: file_service.UploadFileResponse
+	(*ExtractedFile)(nil),              // 6: file_service.ExtractedFile
+	(*ExtractError)(nil),               // 7: file_service.ExtractError
+	(*DownloadFileRequest)(nil),        // 8: file_service.DownloadFileRequest
+	(*DownloadFileResponse)(nil),       // 9: file_service.DownloadFileResponse
+	(*ListFilesRequest)(nil),           // 10: file_service.ListFilesRequest
+	(*FileMetadata)(nil),               // 11: file_service.FileMetadata
+	(*ImageInfo)(nil),                  // 12: file_service.ImageInfo
+	(*ListFilesResponse)(nil),          // 13: file_service.ListFilesResponse
+	(*FileVersion)(nil),                // 14: file_service.FileVersion
+	(*ListFileVersionsRequest)(nil),    // 15: file_service.ListFileVersionsRequest
+	(*ListFileVersionsResponse)(nil),   // 16: file_service.ListFileVersionsResponse
+	(*RestoreFileVersionRequest)(nil),  // 17: file_service.RestoreFileVersionRequest
+	(*RestoreFileVersionResponse)(nil), // 18: file_service.RestoreFileVersionResponse
+	(*CopyFileRequest)(nil),            // 19: file_service.CopyFileRequest
+	(*CopyFileResponse)(nil),           // 20: file_service.CopyFileResponse
+	(*MoveFileRequest)(nil),            // 21: file_service.MoveFileRequest
+	(*MoveFileResponse)(nil),           // 22: file_service.MoveFileResponse
+	(*DeleteFileRequest)(nil),          // 23: file_service.DeleteFileRequest
+	(*DeleteFileResponse)(nil),         // 24: file_service.DeleteFileResponse
+	(*TrashItem)(nil),                  // 25: file_service.TrashItem
+	(*ListTrashRequest)(nil),           // 26: file_service.ListTrashRequest
+	(*ListTrashResponse)(nil),          // 27: file_service.ListTrashResponse
+	(*RestoreFromTrashRequest)(nil),    // 28: file_service.RestoreFromTrashRequest
+	(*RestoreFromTrashResponse)(nil),   // 29: file_service.RestoreFromTrashResponse
+	(*EmptyTrashRequest)(nil),          // 30: file_service.EmptyTrashRequest
+	(*EmptyTrashResponse)(nil),         // 31: file_service.EmptyTrashResponse
+	(*GetImageRenditionRequest)(nil),   // 32: file_service.GetImageRenditionRequest
+	(*GetImageRenditionResponse)(nil),  // 33: file_service.GetImageRenditionResponse
+	(*DownloadArchiveRequest)(nil),     // 34: file_service.DownloadArchiveRequest
+	(*DownloadArchiveResponse)(nil),    // 35: file_service.DownloadArchiveResponse
+	(*StatOp)(nil),                     // 36: file_service.StatOp
+	(*SetAttributesOp)(nil),            // 37: file_service.SetAttributesOp
+	(*BatchRequest)(nil),               // 38: file_service.BatchRequest
+	(*BatchResponse)(nil),              // 39: file_service.BatchResponse
+	(*WatchFilesRequest)(nil),          // 40: file_service.WatchFilesRequest
+	(*FileEvent)(nil),                  // 41: file_service.FileEvent
+	nil,                                // 42: file_service.FileMetadata.AttributesEntry
+	nil,                                // 43: file_service.SetAttributesOp.AttributesEntry
+	(*timestamp.Timestamp)(nil),        // 44: google.protobuf.Timestamp
 }
 var file_internal_proto_file_service_proto_depIdxs = []int32{
-	7, // 0: file_service.FileMetadata.created_at:type_name -> google.protobuf.Timestamp
-	7, // 1: file_service.FileMetadata.updated_at:type_name -> google.protobuf.Timestamp
-	5, // 2: file_service.ListFilesResponse.files:type_name -> file_service.FileMetadata
-	0, // 3: file_service.FileService.UploadFile:input_type -> file_service.UploadFileRequest
-	2, // 4: file_service.FileService.DownloadFile:input_type -> file_service.DownloadFileRequest
-	4, // 5: file_service.FileService.ListFiles:input_type -> file_service.ListFilesRequest
-	1, // 6: file_service.FileService.UploadFile:output_type -> file_service.UploadFileResponse
-	3, // 7: file_service.FileService.DownloadFile:output_type -> file_service.DownloadFileResponse
-	6, // 8: file_service.FileService.ListFiles:output_type -> file_service.ListFilesResponse
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	44, // 0: file_service.UploadFileRequest.expires_at:type_name -> google.protobuf.Timestamp
+	6,  // 1: file_service.UploadFileResponse.created:type_name -> file_service.ExtractedFile
+	7,  // 2: file_service.UploadFileResponse.errors:type_name -> file_service.ExtractError
+	44, // 3: file_service.FileMetadata.created_at:type_name -> google.protobuf.Timestamp
+	44, // 4: file_service.FileMetadata.updated_at:type_name -> google.protobuf.Timestamp
+	44, // 5: file_service.FileMetadata.expires_at:type_name -> google.protobuf.Timestamp
+	12, // 6: file_service.FileMetadata.image_info:type_name -> file_service.ImageInfo
+	42, // 7: file_service.FileMetadata.attributes:type_name -> file_service.FileMetadata.AttributesEntry
+	44, // 8: file_service.ImageInfo.captured_at:type_name -> google.protobuf.Timestamp
+	11, // 9: file_service.ListFilesResponse.files:type_name -> file_service.FileMetadata
+	44, // 10: file_service.FileVersion.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 11: file_service.ListFileVersionsResponse.versions:type_name -> file_service.FileVersion
+	44, // 12: file_service.TrashItem.deleted_at:type_name -> google.protobuf.Timestamp
+	25, // 13: file_service.ListTrashResponse.items:type_name -> file_service.TrashItem
+	0,  // 14: file_service.GetImageRenditionRequest.fit:type_name -> file_service.FitMode
+	1,  // 15: file_service.GetImageRenditionRequest.format:type_name -> file_service.ImageFormat
+	2,  // 16: file_service.DownloadArchiveRequest.format:type_name -> file_service.ArchiveFormat
+	43, // 17: file_service.SetAttributesOp.attributes:type_name -> file_service.SetAttributesOp.AttributesEntry
+	36, // 18: file_service.BatchRequest.stat:type_name -> file_service.StatOp
+	23, // 19: file_service.BatchRequest.delete:type_name -> file_service.DeleteFileRequest
+	19, // 20: file_service.BatchRequest.copy:type_name -> file_service.CopyFileRequest
+	37, // 21: file_service.BatchRequest.set_attributes:type_name -> file_service.SetAttributesOp
+	11, // 22: file_service.BatchResponse.stat:type_name -> file_service.FileMetadata
+	24, // 23: file_service.BatchResponse.delete:type_name -> file_service.DeleteFileResponse
+	20, // 24: file_service.BatchResponse.copy:type_name -> file_service.CopyFileResponse
+	11, // 25: file_service.BatchResponse.set_attributes:type_name -> file_service.FileMetadata
+	3,  // 26: file_service.FileEvent.type:type_name -> file_service.FileEventType
+	44, // 27: file_service.FileEvent.time:type_name -> google.protobuf.Timestamp
+	4,  // 28: file_service.FileService.UploadFile:input_type -> file_service.UploadFileRequest
+	8,  // 29: file_service.FileService.DownloadFile:input_type -> file_service.DownloadFileRequest
+	10, // 30: file_service.FileService.ListFiles:input_type -> file_service.ListFilesRequest
+	15, // 31: file_service.FileService.ListFileVersions:input_type -> file_service.ListFileVersionsRequest
+	17, // 32: file_service.FileService.RestoreFileVersion:input_type -> file_service.RestoreFileVersionRequest
+	19, // 33: file_service.FileService.CopyFile:input_type -> file_service.CopyFileRequest
+	21, // 34: file_service.FileService.MoveFile:input_type -> file_service.MoveFileRequest
+	23, // 35: file_service.FileService.DeleteFile:input_type -> file_service.DeleteFileRequest
+	26, // 36: file_service.FileService.ListTrash:input_type -> file_service.ListTrashRequest
+	28, // 37: file_service.FileService.RestoreFromTrash:input_type -> file_service.RestoreFromTrashRequest
+	30, // 38: file_service.FileService.EmptyTrash:input_type -> file_service.EmptyTrashRequest
+	32, // 39: file_service.FileService.GetImageRendition:input_type -> file_service.GetImageRenditionRequest
+	34, // 40: file_service.FileService.DownloadArchive:input_type -> file_service.DownloadArchiveRequest
+	38, // 41: file_service.FileService.Batch:input_type -> file_service.BatchRequest
+	40, // 42: file_service.FileService.WatchFiles:input_type -> file_service.WatchFilesRequest
+	5,  // 43: file_service.FileService.UploadFile:output_type -> file_service.UploadFileResponse
+	9,  // 44: file_service.FileService.DownloadFile:output_type -> file_service.DownloadFileResponse
+	13, // 45: file_service.FileService.ListFiles:output_type -> file_service.ListFilesResponse
+	16, // 46: file_service.FileService.ListFileVersions:output_type -> file_service.ListFileVersionsResponse
+	18, // 47: file_service.FileService.RestoreFileVersion:output_type -> file_service.RestoreFileVersionResponse
+	20, // 48: file_service.FileService.CopyFile:output_type -> file_service.CopyFileResponse
+	22, // 49: file_service.FileService.MoveFile:output_type -> file_service.MoveFileResponse
+	24, // 50: file_service.FileService.DeleteFile:output_type -> file_service.DeleteFileResponse
+	27, // 51: file_service.FileService.ListTrash:output_type -> file_service.ListTrashResponse
+	29, // 52: file_service.FileService.RestoreFromTrash:output_type -> file_service.RestoreFromTrashResponse
+	31, // 53: file_service.FileService.EmptyTrash:output_type -> file_service.EmptyTrashResponse
+	33, // 54: file_service.FileService.GetImageRendition:output_type -> file_service.GetImageRenditionResponse
+	35, // 55: file_service.FileService.DownloadArchive:output_type -> file_service.DownloadArchiveResponse
+	39, // 56: file_service.FileService.Batch:output_type -> file_service.BatchResponse
+	41, // 57: file_service.FileService.WatchFiles:output_type -> file_service.FileEvent
+	43, // [43:58] is the sub-list for method output_type
+	28, // [28:43] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_file_service_proto_init() }
@@ -420,18 +2982,31 @@ func file_internal_proto_file_service_proto_init() {
 	if File_internal_proto_file_service_proto != nil {
 		return
 	}
+	file_internal_proto_file_service_proto_msgTypes[34].OneofWrappers = []any{
+		(*BatchRequest_Stat)(nil),
+		(*BatchRequest_Delete)(nil),
+		(*BatchRequest_Copy)(nil),
+		(*BatchRequest_SetAttributes)(nil),
+	}
+	file_internal_proto_file_service_proto_msgTypes[35].OneofWrappers = []any{
+		(*BatchResponse_Stat)(nil),
+		(*BatchResponse_Delete)(nil),
+		(*BatchResponse_Copy)(nil),
+		(*BatchResponse_SetAttributes)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_file_service_proto_rawDesc), len(file_internal_proto_file_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      4,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_internal_proto_file_service_proto_goTypes,
 		DependencyIndexes: file_internal_proto_file_service_proto_depIdxs,
+		EnumInfos:         file_internal_proto_file_service_proto_enumTypes,
 		MessageInfos:      file_internal_proto_file_service_proto_msgTypes,
 	}.Build()
 	File_internal_proto_file_service_proto = out.File
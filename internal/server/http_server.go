@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"time"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+type HTTPServer struct {
+	server   *http.Server
+	listener net.Listener
+	addr     string
+	logger   *zap.Logger
+}
+
+func NewHTTPServer(addr string, handler http.Handler, logger *zap.Logger) (*HTTPServer, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen error: %w", err)
+	}
+
+	return &HTTPServer{
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		listener: lis,
+		addr:     addr,
+		logger:   logger,
+	}, nil
+}
+
+func (s *HTTPServer) StartServer(ctx context.Context) error {
+	l := logger.FromContext(ctx)
+	// Requests inherit the logger from ctx but not its cancellation, so that
+	// shutdown lets in-flight transfers finish.
+	base := context.WithoutCancel(ctx)
+	s.server.BaseContext = func(net.Listener) context.Context { return base }
+
+	l.Infof("HTTP listening on %s", s.addr)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		l.Info("Context canceled — stopping HTTP server")
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			// Long-lived streams such as WatchFiles never finish on their own.
+			l.Warnf("HTTP shutdown error, closing remaining connections: %v", err)
+			_ = s.server.Close()
+		}
+		return nil
+	case err := <-serveErr:
+		if err != nil {
+			l.Errorf("HTTP Serve error: %v", err)
+			return fmt.Errorf("http serve error: %w", err)
+		}
+		return nil
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
 )
 
 type GRPCServer struct {
@@ -16,23 +17,68 @@ type GRPCServer struct {
 	ctrl     *controller.FileServiceHandler
 	addr     string
 	logger   *zap.Logger
+	web      *webServer
 }
 
-func NewGRPCServer(addr string, ctrl *controller.FileServiceHandler, logger *zap.Logger) (*GRPCServer, error) {
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	unary  []grpc.UnaryServerInterceptor
+	stream []grpc.StreamServerInterceptor
+}
+
+// WithUnaryInterceptors runs the interceptors, in order, around every unary
+// call, native or web.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *serverOptions) {
+		o.unary = append(o.unary, interceptors...)
+	}
+}
+
+// WithStreamInterceptors runs the interceptors, in order, around every
+// streaming call, native or web.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *serverOptions) {
+		o.stream = append(o.stream, interceptors...)
+	}
+}
+
+func NewGRPCServer(addr string, ctrl *controller.FileServiceHandler, logger *zap.Logger, opts ...Option) (*GRPCServer, error) {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, fmt.Errorf("listen error: %w", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ForceServerCodecV2(controller.Codec()),
+		grpc.ChainUnaryInterceptor(o.unary...),
+		grpc.ChainStreamInterceptor(o.stream...),
+	)
 	pb.RegisterFileServiceServer(grpcServer, ctrl)
 	reflection.Register(grpcServer)
 
+	// The web server shares the codec and interceptors, so that web calls
+	// go through the same pipeline as native ones.
+	web := newWebServer(controller.Codec(), o.unary, o.stream)
+	pb.RegisterFileServiceServer(web, ctrl)
+
 	return &GRPCServer{
 		server:   grpcServer,
 		listener: lis,
 		ctrl:     ctrl,
 		addr:     addr,
 		logger:   logger,
+		web:      web,
 	}, nil
 }
+
+// WebHandler serves the same handlers over gRPC-Web and the Connect protocol,
+// for browsers on the origins allowed by cors.
+func (s *GRPCServer) WebHandler(cors CORSConfig) http.Handler {
+	return withCORS(s.web, cors)
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSConfig struct {
+	AllowedOrigins []string // "*" allows any origin
+	MaxAge         time.Duration
+}
+
+// webServer serves registered gRPC services over gRPC-Web and the Connect
+// protocol. Both work on HTTP/1.1, so browsers can call the service directly.
+// Requests are dispatched to the generated method handlers through the codec
+// and interceptors of the gRPC server, so every RPC behaves exactly as it does
+// over native gRPC.
+type webServer struct {
+	methods map[string]webMethod
+	codec   encoding.CodecV2
+	unary   grpc.UnaryServerInterceptor
+	stream  grpc.StreamServerInterceptor
+}
+
+type webMethod struct {
+	impl   any
+	unary  *grpc.MethodDesc
+	stream *grpc.StreamDesc
+}
+
+func newWebServer(codec encoding.CodecV2, unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor) *webServer {
+	return &webServer{
+		methods: make(map[string]webMethod),
+		codec:   codec,
+		unary:   chainUnary(unary),
+		stream:  chainStream(stream),
+	}
+}
+
+// RegisterService implements grpc.ServiceRegistrar.
+func (ws *webServer) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	for i := range desc.Methods {
+		md := &desc.Methods[i]
+		ws.methods["/"+desc.ServiceName+"/"+md.MethodName] = webMethod{impl: impl, unary: md}
+	}
+	for i := range desc.Streams {
+		sd := &desc.Streams[i]
+		ws.methods["/"+desc.ServiceName+"/"+sd.StreamName] = webMethod{impl: impl, stream: sd}
+	}
+}
+
+func (ws *webServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := logger.FromContext(r.Context())
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	proto, ok := detectProtocol(r.Header.Get("Content-Type"), ws.codec)
+	if !ok {
+		l.Warnw("unsupported web content type", "content_type", r.Header.Get("Content-Type"), "path", r.URL.Path)
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	l.Infow("web call", "method", r.URL.Path, "protocol", proto.name())
+
+	m, ok := ws.methods[r.URL.Path]
+	if !ok {
+		l.Warnw("unknown web method", "method", r.URL.Path)
+		newWebStream(r.Context(), r, w, proto).finish(status.Errorf(codes.Unimplemented, "unknown method %s", r.URL.Path))
+		return
+	}
+	if proto.kind == connectUnary && m.unary == nil {
+		newWebStream(r.Context(), r, w, proto).finish(status.Errorf(codes.Unimplemented, "streaming method %s needs a streaming content type", r.URL.Path))
+		return
+	}
+
+	ctx, cancel, err := requestContext(r, proto)
+	if err != nil {
+		l.Warnw("invalid web request", "error", err, "method", r.URL.Path)
+		newWebStream(r.Context(), r, w, proto).finish(err)
+		return
+	}
+	defer cancel()
+
+	// Client and bidi streams read the body while responses are written.
+	_ = http.NewResponseController(w).EnableFullDuplex()
+
+	s := newWebStream(ctx, r, w, proto)
+	// A handler may leave a goroutine receiving, as UploadFile does; the body
+	// must not be read once ServeHTTP has returned.
+	defer s.closeBody()
+	if m.unary != nil {
+		dec := func(msg any) error {
+			if err := s.RecvMsg(msg); err != io.EOF {
+				return err
+			}
+			return status.Errorf(codes.InvalidArgument, "request message missing")
+		}
+		resp, err := m.unary.Handler(m.impl, s.Context(), dec, ws.unary)
+		if err == nil {
+			err = s.SendMsg(resp)
+		}
+		s.finish(err)
+		return
+	}
+	if ws.stream == nil {
+		s.finish(m.stream.Handler(m.impl, s))
+		return
+	}
+	info := &grpc.StreamServerInfo{
+		FullMethod:     r.URL.Path,
+		IsClientStream: m.stream.ClientStreams,
+		IsServerStream: m.stream.ServerStreams,
+	}
+	s.finish(ws.stream(m.impl, s, info, m.stream.Handler))
+}
+
+// chainUnary combines interceptors into one that runs them in order, as
+// grpc.ChainUnaryInterceptor does.
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i > 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return interceptors[0](ctx, req, info, next)
+	}
+}
+
+// chainStream combines interceptors into one that runs them in order, as
+// grpc.ChainStreamInterceptor does.
+func chainStream(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	if len(interceptors) == 0 {
+		return nil
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i > 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(srv any, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, h)
+			}
+		}
+		return interceptors[0](srv, ss, info, next)
+	}
+}
+
+// exposedHeaders are the response headers browsers must be allowed to read
+// for the protocols to work across origins.
+var exposedHeaders = strings.Join([]string{
+	"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin",
+	"Connect-Content-Encoding", "Connect-Accept-Encoding",
+}, ", ")
+
+// withCORS lets browsers on the configured origins call next.
+func withCORS(next http.Handler, cors CORSConfig) http.Handler {
+	allowed := make(map[string]bool, len(cors.AllowedOrigins))
+	for _, o := range cors.AllowedOrigins {
+		allowed[strings.TrimSpace(o)] = true
+	}
+	maxAge := ""
+	if cors.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(cors.MaxAge.Seconds()))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		hdr := w.Header()
+		ok := allowed["*"] || allowed[origin]
+		switch {
+		case allowed[origin]:
+			hdr.Add("Vary", "Origin")
+			hdr.Set("Access-Control-Allow-Origin", origin)
+		case ok:
+			// Any origin: answer with the wildcard rather than reflect the
+			// origin, so the response never looks origin specific.
+			hdr.Set("Access-Control-Allow-Origin", "*")
+		default:
+			hdr.Add("Vary", "Origin")
+		}
+		if ok {
+			hdr.Set("Access-Control-Expose-Headers", exposedHeaders)
+		}
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Preflight: without the allow headers the browser refuses the call.
+		if ok {
+			hdr.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			if h := r.Header.Get("Access-Control-Request-Headers"); h != "" {
+				hdr.Set("Access-Control-Allow-Headers", h)
+			}
+			if maxAge != "" {
+				hdr.Set("Access-Control-Max-Age", maxAge)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type protocolKind int
+
+const (
+	grpcWeb       protocolKind = iota // enveloped messages, status in a trailer frame
+	connectUnary                      // bare message body, errors as JSON with an HTTP status
+	connectStream                     // enveloped messages, status in an end-stream frame
+)
+
+type webProtocol struct {
+	kind  protocolKind
+	text  bool // grpc-web-text: the body is base64 encoded
+	codec webCodec
+}
+
+// detectProtocol picks the protocol of a request by its content type. Binary
+// messages go through codec, the codec of the gRPC server.
+func detectProtocol(contentType string, codec encoding.CodecV2) (webProtocol, bool) {
+	pc := protoCodec{codec: codec}
+	ct, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(ct)) {
+	case "application/grpc-web", "application/grpc-web+proto":
+		return webProtocol{kind: grpcWeb, codec: pc}, true
+	case "application/grpc-web-text", "application/grpc-web-text+proto":
+		return webProtocol{kind: grpcWeb, text: true, codec: pc}, true
+	case "application/connect+proto":
+		return webProtocol{kind: connectStream, codec: pc}, true
+	case "application/connect+json":
+		return webProtocol{kind: connectStream, codec: jsonCodec{}}, true
+	case "application/proto":
+		return webProtocol{kind: connectUnary, codec: pc}, true
+	case "application/json":
+		return webProtocol{kind: connectUnary, codec: jsonCodec{}}, true
+	}
+	return webProtocol{}, false
+}
+
+func (p webProtocol) name() string {
+	switch {
+	case p.kind == grpcWeb && p.text:
+		return "grpc-web-text"
+	case p.kind == grpcWeb:
+		return "grpc-web"
+	}
+	return "connect"
+}
+
+func (p webProtocol) contentType() string {
+	switch {
+	case p.kind == grpcWeb && p.text:
+		return "application/grpc-web-text+proto"
+	case p.kind == grpcWeb:
+		return "application/grpc-web+proto"
+	case p.kind == connectStream:
+		return "application/connect+" + p.codec.name()
+	}
+	return "application/" + p.codec.name()
+}
+
+type webCodec interface {
+	name() string
+	marshal(m any) ([]byte, error)
+	unmarshal(data []byte, m any) error
+}
+
+type protoCodec struct {
+	codec encoding.CodecV2
+}
+
+func (protoCodec) name() string { return "proto" }
+
+func (c protoCodec) marshal(m any) ([]byte, error) {
+	data, err := c.codec.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Free()
+	return data.Materialize(), nil
+}
+
+func (c protoCodec) unmarshal(data []byte, m any) error {
+	return c.codec.Unmarshal(mem.BufferSlice{mem.SliceBuffer(data)}, m)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) name() string { return "json" }
+
+func (jsonCodec) marshal(m any) ([]byte, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", m)
+	}
+	return protojson.Marshal(msg)
+}
+
+func (jsonCodec) unmarshal(data []byte, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto message", m)
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, msg)
+}
+
+// Request headers that belong to the transport rather than to the call.
+var transportHeaders = map[string]bool{
+	"content-type":             true,
+	"content-length":           true,
+	"content-encoding":         true,
+	"accept-encoding":          true,
+	"connection":               true,
+	"host":                     true,
+	"origin":                   true,
+	"te":                       true,
+	"x-grpc-web":               true,
+	"grpc-timeout":             true,
+	"grpc-encoding":            true,
+	"grpc-accept-encoding":     true,
+	"connect-protocol-version": true,
+	"connect-timeout-ms":       true,
+	"connect-content-encoding": true,
+	"connect-accept-encoding":  true,
+}
+
+// requestContext turns request headers into incoming gRPC metadata and
+// applies the deadline the client asked for.
+func requestContext(r *http.Request, p webProtocol) (context.Context, context.CancelFunc, error) {
+	if enc := requestEncoding(r.Header, p); enc != "" && enc != "identity" {
+		return nil, nil, status.Errorf(codes.Unimplemented, "compression %q is not supported", enc)
+	}
+
+	md := metadata.MD{}
+	for k, vs := range r.Header {
+		key := strings.ToLower(k)
+		if transportHeaders[key] || strings.HasPrefix(key, "access-control-") {
+			continue
+		}
+		for _, v := range vs {
+			if strings.HasSuffix(key, "-bin") {
+				b, err := decodeBinaryHeader(v)
+				if err != nil {
+					return nil, nil, status.Errorf(codes.InvalidArgument, "malformed binary header %s", k)
+				}
+				v = string(b)
+			}
+			md.Append(key, v)
+		}
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	timeout, err := requestTimeout(r.Header, p)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+func requestEncoding(h http.Header, p webProtocol) string {
+	switch p.kind {
+	case grpcWeb:
+		return h.Get("Grpc-Encoding")
+	case connectStream:
+		return h.Get("Connect-Content-Encoding")
+	}
+	return h.Get("Content-Encoding")
+}
+
+func requestTimeout(h http.Header, p webProtocol) (time.Duration, error) {
+	if p.kind != grpcWeb {
+		v := h.Get("Connect-Timeout-Ms")
+		if v == "" {
+			return 0, nil
+		}
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms <= 0 || len(v) > 10 {
+			return 0, fmt.Errorf("invalid Connect-Timeout-Ms %q", v)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	v := h.Get("Grpc-Timeout")
+	if v == "" {
+		return 0, nil
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if !ok || err != nil || n <= 0 || len(v) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", v)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func decodeBinaryHeader(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
+
+// connectCodes are the Connect protocol names of the gRPC status codes.
+var connectCodes = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "data_loss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+// connectHTTPStatus is the HTTP status of a failed Connect unary call.
+func connectHTTPStatus(c codes.Code) int {
+	switch c {
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
+
+// encodeGRPCMessage percent-encodes a status message the way grpc-message
+// requires.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
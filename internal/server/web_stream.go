@@ -0,0 +1,421 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxWebMessageSize = 4 << 20 // matches the default gRPC receive limit
+
+const (
+	flagCompressed = 0x01
+	flagEndStream  = 0x02 // Connect end-stream message
+	flagTrailer    = 0x80 // gRPC-Web trailer frame
+)
+
+// webStream implements grpc.ServerStream on top of one HTTP request, framing
+// messages according to the request's protocol.
+type webStream struct {
+	ctx   context.Context
+	proto webProtocol
+	body  io.Reader
+	w     http.ResponseWriter
+	rc    *http.ResponseController
+	out   io.Writer      // w, or enc for grpc-web-text
+	enc   io.WriteCloser // base64 encoder of the whole grpc-web-text response
+
+	readMu   sync.Mutex // held while a message is read from body
+	recvDone bool
+
+	mu          sync.Mutex
+	header      metadata.MD
+	trailer     metadata.MD
+	sentHeader  bool // header metadata is final
+	wroteHeader bool // the HTTP response header is written
+	reading     bool
+	bodyClosed  bool
+}
+
+var _ grpc.ServerStream = (*webStream)(nil)
+
+func newWebStream(ctx context.Context, r *http.Request, w http.ResponseWriter, p webProtocol) *webStream {
+	s := &webStream{
+		proto:   p,
+		body:    r.Body,
+		w:       w,
+		rc:      http.NewResponseController(w),
+		out:     w,
+		header:  metadata.MD{},
+		trailer: metadata.MD{},
+	}
+	if p.text {
+		s.body = newBase64Reader(r.Body)
+		s.enc = base64.NewEncoder(base64.StdEncoding, w)
+		s.out = s.enc
+	}
+	s.ctx = grpc.NewContextWithServerTransportStream(ctx, &webTransportStream{s: s, method: r.URL.Path})
+	return s
+}
+
+func (s *webStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *webStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sentHeader {
+		return errors.New("headers already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *webStream) SendHeader(md metadata.MD) error {
+	if err := s.SetHeader(md); err != nil {
+		return err
+	}
+	if s.proto.kind == connectUnary {
+		// The HTTP status depends on the outcome, so the header waits for
+		// the response or the error.
+		s.mu.Lock()
+		s.sentHeader = true
+		s.mu.Unlock()
+		return nil
+	}
+	s.writeHeader()
+	return nil
+}
+
+func (s *webStream) SetTrailer(md metadata.MD) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trailer = metadata.Join(s.trailer, md)
+}
+
+func (s *webStream) RecvMsg(m any) error {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+	s.mu.Lock()
+	if s.bodyClosed {
+		s.mu.Unlock()
+		return status.Errorf(codes.Canceled, "call already finished")
+	}
+	s.reading = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.reading = false
+		s.mu.Unlock()
+	}()
+	return s.recvMsg(m)
+}
+
+// closeBody stops reads of the request body: a read in progress is
+// interrupted and later ones fail. It returns once no read is running, unless
+// the connection cannot interrupt reads.
+func (s *webStream) closeBody() {
+	s.mu.Lock()
+	s.bodyClosed = true
+	reading := s.reading
+	s.mu.Unlock()
+	if reading && s.rc.SetReadDeadline(time.Now()) != nil {
+		return
+	}
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+}
+
+func (s *webStream) recvMsg(m any) error {
+	if s.proto.kind == connectUnary {
+		if s.recvDone {
+			return io.EOF
+		}
+		s.recvDone = true
+		data, err := io.ReadAll(io.LimitReader(s.body, maxWebMessageSize+1))
+		if err != nil {
+			return status.Errorf(codes.Canceled, "failed to read request: %v", err)
+		}
+		if len(data) > maxWebMessageSize {
+			return status.Errorf(codes.ResourceExhausted, "request message larger than %d bytes", maxWebMessageSize)
+		}
+		return s.decode(data, m)
+	}
+
+	var hdr [5]byte
+	if _, err := io.ReadFull(s.body, hdr[:]); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return status.Errorf(codes.InvalidArgument, "malformed request frame: %v", err)
+	}
+	if hdr[0]&flagCompressed != 0 {
+		return status.Errorf(codes.Unimplemented, "compressed messages are not supported")
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxWebMessageSize {
+		return status.Errorf(codes.ResourceExhausted, "request message larger than %d bytes", maxWebMessageSize)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(s.body, data); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed request frame: %v", err)
+	}
+	return s.decode(data, m)
+}
+
+func (s *webStream) decode(data []byte, m any) error {
+	if err := s.proto.codec.unmarshal(data, m); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to decode request: %v", err)
+	}
+	return nil
+}
+
+func (s *webStream) SendMsg(m any) error {
+	data, err := s.proto.codec.marshal(m)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to encode response: %v", err)
+	}
+	if s.proto.kind == connectUnary {
+		// Unary handlers have returned by now, so their trailers are final
+		// and travel as prefixed headers.
+		s.mu.Lock()
+		for k, vs := range s.trailer {
+			for _, v := range vs {
+				s.w.Header().Add("Trailer-"+k, headerValue(k, v))
+			}
+		}
+		s.mu.Unlock()
+		s.writeHeader()
+		if _, err := s.w.Write(data); err != nil {
+			return status.Errorf(codes.Unavailable, "failed to send response: %v", err)
+		}
+		return nil
+	}
+	s.writeHeader()
+	return s.writeFrame(0, data)
+}
+
+func (s *webStream) writeHeader() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wroteHeader {
+		return
+	}
+	s.sentHeader, s.wroteHeader = true, true
+	hdr := s.w.Header()
+	hdr.Set("Content-Type", s.proto.contentType())
+	for k, vs := range s.header {
+		for _, v := range vs {
+			hdr.Add(k, headerValue(k, v))
+		}
+	}
+	s.w.WriteHeader(http.StatusOK)
+}
+
+func (s *webStream) writeFrame(flags byte, data []byte) error {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	copy(frame[5:], data)
+	// A grpc-web-text response is one base64 stream; up to two bytes of a
+	// frame wait in enc for the next frame or the end of the response.
+	if _, err := s.out.Write(frame); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to send response: %v", err)
+	}
+	_ = s.rc.Flush()
+	return nil
+}
+
+// finish reports the outcome of the call, which err carries.
+func (s *webStream) finish(err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+
+	switch s.proto.kind {
+	case connectUnary:
+		s.mu.Lock()
+		wrote := s.wroteHeader
+		s.mu.Unlock()
+		if err == nil {
+			return
+		}
+		if wrote {
+			// Only a failed write of the response gets here; the client
+			// is gone.
+			return
+		}
+		s.writeConnectError(st)
+	case connectStream:
+		s.writeHeader()
+		end := connectEndStream{Metadata: s.trailerMap()}
+		if err != nil {
+			end.Error = &connectError{Code: connectCodes[st.Code()], Message: st.Message()}
+		}
+		data, _ := json.Marshal(end)
+		_ = s.writeFrame(flagEndStream, data)
+	default:
+		s.mu.Lock()
+		if !s.wroteHeader {
+			// Trailers-only response: clients may read the status from headers.
+			s.w.Header().Set("Grpc-Status", strconv.Itoa(int(st.Code())))
+			s.w.Header().Set("Grpc-Message", encodeGRPCMessage(st.Message()))
+		}
+		s.mu.Unlock()
+		s.writeHeader()
+		var b strings.Builder
+		fmt.Fprintf(&b, "grpc-status: %d\r\ngrpc-message: %s\r\n", st.Code(), encodeGRPCMessage(st.Message()))
+		for k, vs := range s.trailerMap() {
+			for _, v := range vs {
+				fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+			}
+		}
+		_ = s.writeFrame(flagTrailer, []byte(b.String()))
+		if s.enc != nil {
+			_ = s.enc.Close()
+			_ = s.rc.Flush()
+		}
+	}
+}
+
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type connectEndStream struct {
+	Error    *connectError       `json:"error,omitempty"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+}
+
+func (s *webStream) writeConnectError(st *status.Status) {
+	hdr := s.w.Header()
+	s.mu.Lock()
+	for k, vs := range s.header {
+		for _, v := range vs {
+			hdr.Add(k, headerValue(k, v))
+		}
+	}
+	for k, vs := range s.trailer {
+		for _, v := range vs {
+			hdr.Add("Trailer-"+k, headerValue(k, v))
+		}
+	}
+	s.sentHeader, s.wroteHeader = true, true
+	s.mu.Unlock()
+
+	hdr.Set("Content-Type", "application/json")
+	s.w.WriteHeader(connectHTTPStatus(st.Code()))
+	_ = json.NewEncoder(s.w).Encode(connectError{Code: connectCodes[st.Code()], Message: st.Message()})
+}
+
+func (s *webStream) trailerMap() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.trailer) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(s.trailer))
+	for k, vs := range s.trailer {
+		for _, v := range vs {
+			out[k] = append(out[k], headerValue(k, v))
+		}
+	}
+	return out
+}
+
+// base64Reader decodes a grpc-web-text request body. Clients may end a base64
+// segment with padding after any frame and start a new one, so the body is
+// decoded a segment at a time rather than as a single base64 stream.
+type base64Reader struct {
+	r   *bufio.Reader
+	in  []byte
+	dec []byte
+	out []byte // decoded bytes not read yet
+}
+
+func newBase64Reader(r io.Reader) *base64Reader {
+	const size = 4096
+	return &base64Reader{
+		r:   bufio.NewReaderSize(r, size),
+		in:  make([]byte, size),
+		dec: make([]byte, size/4*3),
+	}
+}
+
+func (d *base64Reader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if err := d.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill decodes the whole 4 byte groups that are buffered, at least one.
+func (d *base64Reader) fill() error {
+	n := min(d.r.Buffered(), len(d.in)) &^ 3
+	in := d.in[:max(n, 4)]
+	if _, err := io.ReadFull(d.r, in); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("truncated base64 body")
+		}
+		return err
+	}
+	d.out = d.dec[:0]
+	for len(in) > 0 {
+		seg := in
+		if i := bytes.IndexByte(in, '='); i >= 0 {
+			seg = in[:(i/4+1)*4]
+		}
+		n, err := base64.StdEncoding.Decode(d.dec[len(d.out):cap(d.dec)], seg)
+		if err != nil {
+			return err
+		}
+		d.out = d.dec[:len(d.out)+n]
+		in = in[len(seg):]
+	}
+	return nil
+}
+
+// headerValue encodes binary metadata, whose keys end in "-bin", as base64.
+func headerValue(key, v string) string {
+	if strings.HasSuffix(key, "-bin") {
+		return base64.RawStdEncoding.EncodeToString([]byte(v))
+	}
+	return v
+}
+
+// webTransportStream lets handlers use grpc.SetHeader and friends on web
+// calls, as they can on native gRPC calls.
+type webTransportStream struct {
+	s      *webStream
+	method string
+}
+
+func (t *webTransportStream) Method() string                  { return t.method }
+func (t *webTransportStream) SetHeader(md metadata.MD) error  { return t.s.SetHeader(md) }
+func (t *webTransportStream) SendHeader(md metadata.MD) error { return t.s.SendHeader(md) }
+func (t *webTransportStream) SetTrailer(md metadata.MD) error {
+	t.s.SetTrailer(md)
+	return nil
+}
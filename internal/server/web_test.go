@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/PianyCoder/test_file_service/internal/controller"
+	"github.com/PianyCoder/test_file_service/internal/fakeserver"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func frame(t *testing.T, flags byte, m proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 5, 5+len(data))
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(data)))
+	return append(b, data...)
+}
+
+type webFrame struct {
+	flags byte
+	data  []byte
+}
+
+func readFrames(t *testing.T, body []byte) []webFrame {
+	t.Helper()
+	var frames []webFrame
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("truncated frame header: %q", body)
+		}
+		n := binary.BigEndian.Uint32(body[1:])
+		if uint32(len(body)-5) < n {
+			t.Fatalf("truncated frame: want %d bytes, have %d", n, len(body)-5)
+		}
+		frames = append(frames, webFrame{flags: body[0], data: body[5 : 5+n]})
+		body = body[5+n:]
+	}
+	return frames
+}
+
+func post(h http.Handler, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/file_service.FileService/ListFiles", body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// countingCodec counts the messages passing through the server codec.
+type countingCodec struct {
+	encoding.CodecV2
+	mu                 sync.Mutex
+	marshal, unmarshal int
+}
+
+func (c *countingCodec) Marshal(v any) (mem.BufferSlice, error) {
+	c.mu.Lock()
+	c.marshal++
+	c.mu.Unlock()
+	return c.CodecV2.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	c.mu.Lock()
+	c.unmarshal++
+	c.mu.Unlock()
+	return c.CodecV2.Unmarshal(data, v)
+}
+
+func TestWebUsesCodecAndInterceptors(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(s string) {
+		mu.Lock()
+		calls = append(calls, s)
+		mu.Unlock()
+	}
+	unary := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			record(name + " " + info.FullMethod)
+			return h(ctx, req)
+		}
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+		record("stream " + info.FullMethod)
+		return h(srv, ss)
+	}
+	codec := &countingCodec{CodecV2: controller.Codec()}
+	fake := fakeserver.New()
+	fake.Put("a", []byte("data"))
+	ws := newWebServer(codec, []grpc.UnaryServerInterceptor{unary("first"), unary("second")}, []grpc.StreamServerInterceptor{stream})
+	pb.RegisterFileServiceServer(ws, fake)
+
+	w := post(ws, "application/grpc-web+proto", bytes.NewReader(frame(t, 0, &pb.ListFilesRequest{})))
+	frames := readFrames(t, w.Body.Bytes())
+	if len(frames) != 2 || !strings.Contains(string(frames[1].data), "grpc-status: 0") {
+		t.Fatalf("frames = %q", frames)
+	}
+	var resp pb.ListFilesResponse
+	if err := proto.Unmarshal(frames[0].data, &resp); err != nil || len(resp.GetFiles()) != 1 {
+		t.Fatalf("response = %v, err = %v", &resp, err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/file_service.FileService/DownloadFile",
+		bytes.NewReader(frame(t, 0, &pb.DownloadFileRequest{Filename: "a"})))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+	ws.ServeHTTP(httptest.NewRecorder(), r)
+
+	want := []string{
+		"first /file_service.FileService/ListFiles",
+		"second /file_service.FileService/ListFiles",
+		"stream /file_service.FileService/DownloadFile",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("interceptor calls = %q, want %q", calls, want)
+	}
+	if codec.unmarshal != 2 || codec.marshal != 2 {
+		t.Errorf("codec unmarshal = %d, marshal = %d, want 2 each", codec.unmarshal, codec.marshal)
+	}
+}
+
+// headerThenFail sends its header metadata before failing.
+type headerThenFail struct {
+	*fakeserver.Server
+}
+
+func (headerThenFail) ListFiles(ctx context.Context, _ *pb.ListFilesRequest) (*pb.ListFilesResponse, error) {
+	if err := grpc.SendHeader(ctx, metadata.Pairs("x-sent", "early")); err != nil {
+		return nil, err
+	}
+	return nil, status.Error(codes.NotFound, "nothing here")
+}
+
+func TestWebErrorAfterHeader(t *testing.T) {
+	ws := newWebServer(controller.Codec(), nil, nil)
+	pb.RegisterFileServiceServer(ws, headerThenFail{fakeserver.New()})
+
+	t.Run("connect", func(t *testing.T) {
+		w := post(ws, "application/proto", bytes.NewReader(nil))
+		if w.Code != http.StatusNotFound || w.Header().Get("X-Sent") != "early" {
+			t.Fatalf("status = %d, header = %v", w.Code, w.Header())
+		}
+		var e connectError
+		if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil || e.Code != "not_found" {
+			t.Errorf("error = %+v, err = %v", e, err)
+		}
+	})
+	t.Run("grpc-web", func(t *testing.T) {
+		w := post(ws, "application/grpc-web+proto", bytes.NewReader(frame(t, 0, &pb.ListFilesRequest{})))
+		frames := readFrames(t, w.Body.Bytes())
+		if len(frames) != 1 || frames[0].flags != flagTrailer {
+			t.Fatalf("frames = %q", frames)
+		}
+		if got := string(frames[0].data); !strings.Contains(got, "grpc-status: 5\r\n") {
+			t.Errorf("trailer = %q", got)
+		}
+	})
+}
+
+func TestWebText(t *testing.T) {
+	fake := fakeserver.New()
+	ws := newWebServer(controller.Codec(), nil, nil)
+	pb.RegisterFileServiceServer(ws, fake)
+
+	// Each frame is encoded on its own, with padding in the middle of the
+	// body, as clients may send it.
+	var body strings.Builder
+	for _, m := range []*pb.UploadFileRequest{{Filename: "t"}, {Chunk: []byte("ab")}, {Chunk: []byte("c")}} {
+		body.WriteString(base64.StdEncoding.EncodeToString(frame(t, 0, m)))
+	}
+	r := httptest.NewRequest(http.MethodPost, "/file_service.FileService/UploadFile", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", "application/grpc-web-text")
+	w := httptest.NewRecorder()
+	ws.ServeHTTP(w, r)
+
+	if data, _ := fake.Get("t"); string(data) != "abc" {
+		t.Errorf("stored %q, want %q", data, "abc")
+	}
+	// The response is a single base64 stream.
+	if strings.Contains(strings.TrimRight(w.Body.String(), "="), "=") {
+		t.Errorf("padding inside the response: %q", w.Body.String())
+	}
+	raw, err := base64.StdEncoding.DecodeString(w.Body.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := readFrames(t, raw)
+	if len(frames) != 2 || !strings.Contains(string(frames[1].data), "grpc-status: 0") {
+		t.Fatalf("frames = %q", frames)
+	}
+	var resp pb.UploadFileResponse
+	if err := proto.Unmarshal(frames[0].data, &resp); err != nil || resp.GetEtag() != fakeserver.ETag([]byte("abc")) {
+		t.Errorf("response = %v, err = %v", &resp, err)
+	}
+}
+
+func TestWebCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    string
+	}{
+		{name: "listed origin", allowed: []string{"https://a.example"}, origin: "https://a.example", want: "https://a.example"},
+		{name: "other origin", allowed: []string{"https://a.example"}, origin: "https://b.example", want: ""},
+		{name: "any origin", allowed: []string{"*"}, origin: "https://b.example", want: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := withCORS(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), CORSConfig{AllowedOrigins: tt.allowed})
+			r := httptest.NewRequest(http.MethodOptions, "/", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// leaveReceiver returns from UploadFile while a goroutine keeps receiving,
+// as the controller's upload receiver may.
+type leaveReceiver struct {
+	*fakeserver.Server
+	recvDone chan error
+}
+
+func (s leaveReceiver) UploadFile(stream grpc.ClientStreamingServer[pb.UploadFileRequest, pb.UploadFileResponse]) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				s.recvDone <- err
+				return
+			}
+		}
+	}()
+	return status.Error(codes.InvalidArgument, "rejected")
+}
+
+// watchedBody notes reads that end after the handler has returned.
+type watchedBody struct {
+	io.ReadCloser
+	returned, late *atomic.Bool
+}
+
+func (b watchedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.returned.Load() {
+		b.late.Store(true)
+	}
+	return n, err
+}
+
+func TestWebBodyNotReadAfterReturn(t *testing.T) {
+	svc := leaveReceiver{Server: fakeserver.New(), recvDone: make(chan error, 1)}
+	ws := newWebServer(controller.Codec(), nil, nil)
+	pb.RegisterFileServiceServer(ws, svc)
+	var returned, late atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = watchedBody{ReadCloser: r.Body, returned: &returned, late: &late}
+		ws.ServeHTTP(w, r)
+		returned.Store(true)
+	}))
+	defer srv.Close()
+
+	// The client keeps the request body open.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() { _, _ = pw.Write(frame(t, 0, &pb.UploadFileRequest{Filename: "f"})) }()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/file_service.FileService/UploadFile", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The body ends when ServeHTTP returns.
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frames := readFrames(t, body); len(frames) != 1 || !strings.Contains(string(frames[0].data), "grpc-status: 3") {
+		t.Fatalf("frames = %q", frames)
+	}
+
+	select {
+	case err := <-svc.recvDone:
+		if err == nil || err == io.EOF {
+			t.Errorf("receive after return = %v, want an error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receiver still reading the body")
+	}
+	if late.Load() {
+		t.Error("body read after ServeHTTP returned")
+	}
+}
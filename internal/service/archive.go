@@ -0,0 +1,207 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"io"
+	"strings"
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTar   ArchiveFormat = "tar"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+func archiveFormatOf(filename string) (ArchiveFormat, bool) {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip, true
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveTar, true
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz, true
+	}
+	return "", false
+}
+
+func (fs *fileService) DownloadArchive(ctx context.Context, filenames []string, prefix string, format ArchiveFormat, writer io.Writer) error {
+	l := logger.FromContext(ctx)
+	l.Infow("service.DownloadArchive called", "files", len(filenames), "prefix", prefix, "format", format)
+	if err := fs.downloadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire download semaphore", "error", err)
+		return fmt.Errorf("failed to acquire download semaphore: %w", err)
+	}
+	defer fs.downloadLimiter.Release(1)
+
+	if format != ArchiveZip && format != ArchiveTarGz {
+		l.Errorw("unsupported archive format", "format", format)
+		return fmt.Errorf("%w: unsupported archive format %q", ErrInvalidArgument, format)
+	}
+	entries, err := fs.archiveEntries(ctx, filenames, prefix)
+	if err != nil {
+		return err
+	}
+
+	aw := newArchiveWriter(writer, format)
+	var total int64
+	for _, m := range entries {
+		n, err := fs.writeArchiveEntry(ctx, aw, m)
+		if err != nil {
+			l.Errorw("failed to add file to archive", "error", err, "filename", m.Name, "bytes_copied", n)
+			return fmt.Errorf("failed to add %s to archive: %w", m.Name, err)
+		}
+		total += n
+	}
+	if err := aw.Close(); err != nil {
+		l.Errorw("failed to finish archive", "error", err)
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	l.Infow("service.DownloadArchive finished", "files", len(entries), "bytes", total)
+	return nil
+}
+
+// archiveEntries resolves the request to file metadata up front, so that a
+// missing file fails the call before any archive bytes are written.
+func (fs *fileService) archiveEntries(ctx context.Context, filenames []string, prefix string) ([]entity.FileMetadata, error) {
+	l := logger.FromContext(ctx)
+	switch {
+	case len(filenames) == 0 && prefix == "":
+		l.Error("archive requires filenames or a prefix")
+		return nil, fmt.Errorf("%w: filenames or prefix required", ErrInvalidArgument)
+	case len(filenames) > 0 && prefix != "":
+		l.Error("archive filenames and prefix are mutually exclusive")
+		return nil, fmt.Errorf("%w: filenames and prefix are mutually exclusive", ErrInvalidArgument)
+	}
+
+	if prefix != "" {
+		all, err := fs.storage.ListAllFilesMetadata(ctx)
+		if err != nil {
+			l.Errorw("storage error list", "error", err)
+			return nil, fmt.Errorf("storage error list: %w", err)
+		}
+		var entries []entity.FileMetadata
+		for _, m := range all {
+			if strings.HasPrefix(m.Name, prefix) {
+				entries = append(entries, m)
+			}
+		}
+		return entries, nil
+	}
+
+	seen := make(map[string]bool, len(filenames))
+	entries := make([]entity.FileMetadata, 0, len(filenames))
+	for _, name := range filenames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if err := validateFilename(ctx, name); err != nil {
+			return nil, err
+		}
+		m, err := fs.storage.StatFile(ctx, name)
+		if err != nil {
+			l.Errorw("storage error on stat", "error", err, "filename", name)
+			return nil, fmt.Errorf("storage error on stat: %w", err)
+		}
+		entries = append(entries, m)
+	}
+	return entries, nil
+}
+
+// errChangedWhileArchiving reports that a file no longer matches the
+// metadata its archive entry was written with.
+func errChangedWhileArchiving(name string) error {
+	return fmt.Errorf("%w: file changed while archiving: %s", storage.ErrPreconditionFailed, name)
+}
+
+func (fs *fileService) writeArchiveEntry(ctx context.Context, aw archiveWriter, m entity.FileMetadata) (int64, error) {
+	// The read is pinned to the listed version, whose size and time the
+	// entry header carries.
+	r, err := fs.storage.GetFileReader(ctx, m.Name, entity.ReadOptions{IfMatch: m.ETag})
+	if errors.Is(err, storage.ErrPreconditionFailed) {
+		return 0, errChangedWhileArchiving(m.Name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("storage error on get: %w", err)
+	}
+	defer r.Close()
+
+	w, err := aw.Create(m)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.CopyN(w, r, m.Size)
+	if errors.Is(err, io.EOF) || errors.Is(err, storage.ErrPreconditionFailed) {
+		return n, errChangedWhileArchiving(m.Name)
+	}
+	if err != nil {
+		return n, err
+	}
+	if extra, _ := r.Read(make([]byte, 1)); extra > 0 {
+		return n, errChangedWhileArchiving(m.Name)
+	}
+	return n, nil
+}
+
+type archiveWriter interface {
+	Create(m entity.FileMetadata) (io.Writer, error)
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) archiveWriter {
+	if format == ArchiveTarGz {
+		gz := gzip.NewWriter(w)
+		return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+	}
+	return &zipWriter{zw: zip.NewWriter(w)}
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) Create(m entity.FileMetadata) (io.Writer, error) {
+	return z.zw.CreateHeader(&zip.FileHeader{Name: m.Name, Method: zip.Deflate, Modified: m.UpdatedAt})
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarGzWriter) Create(m entity.FileMetadata) (io.Writer, error) {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     m.Name,
+		Size:     m.Size,
+		Mode:     0o644,
+		ModTime:  m.UpdatedAt,
+		Format:   tar.FormatPAX,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+)
+
+// maxAttributesSize keeps attributes well within the 2 KiB S3 allows for all
+// user metadata together.
+const maxAttributesSize = 1024
+
+func (fs *fileService) StatFile(ctx context.Context, filename string) (entity.FileMetadata, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.StatFile called", "filename", filename)
+	if err := fs.listLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire list semaphore", "error", err)
+		return entity.FileMetadata{}, fmt.Errorf("failed to acquire list semaphore: %w", err)
+	}
+	defer fs.listLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return entity.FileMetadata{}, err
+	}
+
+	meta, err := fs.storage.StatFile(ctx, filename)
+	if err != nil {
+		l.Errorw("storage error on stat", "error", err, "filename", filename)
+		return entity.FileMetadata{}, fmt.Errorf("storage error on stat: %w", err)
+	}
+	return meta, nil
+}
+
+func (fs *fileService) SetAttributes(ctx context.Context, filename string, attrs map[string]string) (entity.FileMetadata, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.SetAttributes called", "filename", filename, "attributes", len(attrs))
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.FileMetadata{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return entity.FileMetadata{}, err
+	}
+	if err := validateAttributes(ctx, attrs); err != nil {
+		return entity.FileMetadata{}, err
+	}
+
+	meta, err := fs.storage.SetAttributes(ctx, filename, attrs)
+	if err != nil {
+		l.Errorw("storage error on set attributes", "error", err, "filename", filename)
+		return entity.FileMetadata{}, fmt.Errorf("storage error on set attributes: %w", err)
+	}
+	fs.emit(entity.EventUpdated, filename, entity.UploadInfo{Size: meta.Size, ETag: meta.ETag})
+	l.Infow("service.SetAttributes finished", "filename", filename)
+	return meta, nil
+}
+
+func validateAttributes(ctx context.Context, attrs map[string]string) error {
+	l := logger.FromContext(ctx)
+	if len(attrs) == 0 {
+		l.Error("no attributes given")
+		return fmt.Errorf("%w: no attributes given", ErrInvalidArgument)
+	}
+	size := 0
+	for k, v := range attrs {
+		if !isAttributeKey(k) {
+			l.Errorw("invalid attribute key", "key", k)
+			return fmt.Errorf("%w: attribute keys must be lower-case letters, digits and dashes: %q", ErrInvalidArgument, k)
+		}
+		for _, r := range v {
+			if r < 0x20 || r > 0x7e {
+				l.Errorw("invalid attribute value", "key", k)
+				return fmt.Errorf("%w: attribute %q must be printable ASCII", ErrInvalidArgument, k)
+			}
+		}
+		size += len(k) + len(v)
+	}
+	if size > maxAttributesSize {
+		l.Errorw("attributes too large", "size", size)
+		return fmt.Errorf("%w: attributes exceed %d bytes", ErrInvalidArgument, maxAttributesSize)
+	}
+	return nil
+}
+
+func isAttributeKey(k string) bool {
+	if k == "" || len(k) > 64 {
+		return false
+	}
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
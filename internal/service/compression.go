@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+const CompressionNone = "none"
+
+var compressibleExtensions = map[string]bool{
+	".txt": true, ".csv": true, ".tsv": true, ".json": true, ".ndjson": true,
+	".xml": true, ".yaml": true, ".yml": true, ".log": true, ".md": true,
+	".html": true, ".htm": true, ".css": true, ".js": true, ".svg": true,
+}
+
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-ndjson":   true,
+	"application/yaml":       true,
+	"image/svg+xml":          true,
+}
+
+func isCompressible(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if compressibleExtensions[ext] {
+		return true
+	}
+	ct, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	return strings.HasPrefix(ct, "text/") || compressibleTypes[ct]
+}
+
+// resolveCompression maps the per-upload request onto the algorithm to store
+// the file with: an explicit algorithm or "none" wins, otherwise the content
+// type of the file name decides when automatic compression is enabled.
+func (fs *fileService) resolveCompression(filename, requested string) (string, error) {
+	switch {
+	case requested == CompressionNone:
+		return "", nil
+	case requested != "":
+		if !storage.IsSupportedCompression(requested) {
+			return "", fmt.Errorf("%w: unsupported compression %q", ErrInvalidArgument, requested)
+		}
+		return requested, nil
+	case fs.autoCompression != "" && isCompressible(filename):
+		return fs.autoCompression, nil
+	}
+	return "", nil
+}
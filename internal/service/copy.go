@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+)
+
+func (fs *fileService) CopyFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.CopyFile called", "source", src, "destination", dst, "overwrite", overwrite)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if err := validateCopy(ctx, src, dst); err != nil {
+		return entity.UploadInfo{}, err
+	}
+
+	eventType := entity.EventCreated
+	if fs.localEvents() {
+		eventType = fs.writeEventType(ctx, dst)
+	}
+	info, err := fs.storage.CopyFile(ctx, src, dst, overwrite)
+	if err != nil {
+		l.Errorw("storage error on copy", "error", err, "source", src, "destination", dst)
+		return entity.UploadInfo{}, fmt.Errorf("storage error on copy: %w", err)
+	}
+	fs.emit(eventType, dst, info)
+	l.Infow("service.CopyFile finished", "source", src, "destination", dst, "version_id", info.VersionID)
+	return info, nil
+}
+
+func (fs *fileService) MoveFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.MoveFile called", "source", src, "destination", dst, "overwrite", overwrite)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if err := validateCopy(ctx, src, dst); err != nil {
+		return entity.UploadInfo{}, err
+	}
+
+	eventType := entity.EventCreated
+	if fs.localEvents() {
+		eventType = fs.writeEventType(ctx, dst)
+	}
+	info, err := fs.storage.MoveFile(ctx, src, dst, overwrite)
+	if err != nil {
+		l.Errorw("storage error on move", "error", err, "source", src, "destination", dst)
+		return entity.UploadInfo{}, fmt.Errorf("storage error on move: %w", err)
+	}
+	fs.emit(eventType, dst, info)
+	fs.emit(entity.EventDeleted, src, entity.UploadInfo{})
+	l.Infow("service.MoveFile finished", "source", src, "destination", dst, "version_id", info.VersionID)
+	return info, nil
+}
+
+func validateCopy(ctx context.Context, src, dst string) error {
+	if err := validateFilename(ctx, src); err != nil {
+		return err
+	}
+	if err := validateFilename(ctx, dst); err != nil {
+		return err
+	}
+	if src == dst {
+		logger.FromContext(ctx).Errorw("source and destination are the same", "filename", src)
+		return fmt.Errorf("%w: source and destination are the same", ErrInvalidArgument)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/bufpool"
+	entity "github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"io"
+)
+
+func (fs *fileService) DownloadFile(ctx context.Context, filename string, opts entity.ReadOptions, writer io.Writer) error {
+	l := logger.FromContext(ctx)
+	l.Infow("service.DownloadFile called", "filename", filename, "version_id", opts.VersionID, "offset", opts.Offset, "length", opts.Length)
+	r, release, err := fs.openForRead(ctx, filename, opts)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	buf := bufpool.Get(fs.chunkSize)
+	defer bufpool.Put(buf)
+	n, err := io.CopyBuffer(writer, r, buf)
+	if err != nil {
+		l.Errorw("failed to stream file to writer", "error", err, "bytes_copied", n, "filename", filename)
+		return fmt.Errorf("failed to stream file to writer: %w", err)
+	}
+	l.Infow("service.DownloadFile finished", "filename", filename, "bytes", n)
+	return nil
+}
+
+func (fs *fileService) DownloadChunks(ctx context.Context, filename string, opts entity.ReadOptions, chunkSize int, send func([]byte) error) error {
+	l := logger.FromContext(ctx)
+	size := fs.resolveChunkSize(chunkSize)
+	l.Infow("service.DownloadChunks called", "filename", filename, "version_id", opts.VersionID, "offset", opts.Offset, "length", opts.Length, "chunk_size", size)
+	r, release, err := fs.openForRead(ctx, filename, opts)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	buf := bufpool.Get(size)
+	defer bufpool.Put(buf)
+	var total int64
+	for {
+		// Filling whole chunks keeps the message count independent of how
+		// the storage reader happens to split its reads.
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := send(buf[:n]); err != nil {
+				l.Errorw("failed to send chunk", "error", err, "bytes_sent", total, "filename", filename)
+				return fmt.Errorf("failed to send chunk: %w", err)
+			}
+			total += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			l.Errorw("failed to read file", "error", err, "bytes_sent", total, "filename", filename)
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	l.Infow("service.DownloadChunks finished", "filename", filename, "bytes", total)
+	return nil
+}
+
+// openForRead takes a download slot and opens the file. release closes the
+// reader and frees the slot.
+func (fs *fileService) openForRead(ctx context.Context, filename string, opts entity.ReadOptions) (io.Reader, func(), error) {
+	l := logger.FromContext(ctx)
+	if err := fs.downloadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire download semaphore", "error", err)
+		return nil, nil, fmt.Errorf("failed to acquire download semaphore: %w", err)
+	}
+
+	if err := validateFilename(ctx, filename); err != nil {
+		fs.downloadLimiter.Release(1)
+		return nil, nil, err
+	}
+
+	if opts.Offset < 0 {
+		fs.downloadLimiter.Release(1)
+		l.Errorw("invalid negative offset", "offset", opts.Offset)
+		return nil, nil, fmt.Errorf("%w: negative offset %d", storage.ErrInvalidRange, opts.Offset)
+	}
+
+	r, err := fs.storage.GetFileReader(ctx, filename, opts)
+	if err != nil {
+		fs.downloadLimiter.Release(1)
+		l.Errorw("storage error on get", "error", err, "filename", filename)
+		return nil, nil, fmt.Errorf("storage error on get: %w", err)
+	}
+	return r, func() {
+		_ = r.Close()
+		fs.downloadLimiter.Release(1)
+	}, nil
+}
+
+// resolveChunkSize returns the configured chunk size for requested 0 and
+// otherwise requested within [MinChunkSize, maxChunkSize].
+func (fs *fileService) resolveChunkSize(requested int) int {
+	if requested <= 0 {
+		return fs.chunkSize
+	}
+	return min(max(requested, MinChunkSize), fs.maxChunkSize)
+}
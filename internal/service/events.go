@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/events"
+)
+
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// localEvents reports whether this service has to emit change events itself,
+// which is the case while MinIO bucket notifications are not available.
+func (fs *fileService) localEvents() bool {
+	return fs.events != nil && fs.events.Source() == events.SourceLocal
+}
+
+// writeEventType tells whether writing filename creates or updates a file.
+// It costs a stat, so it is only called while emitting events locally.
+func (fs *fileService) writeEventType(ctx context.Context, filename string) entity.EventType {
+	if _, err := fs.storage.StatFile(ctx, filename); err == nil {
+		return entity.EventUpdated
+	}
+	return entity.EventCreated
+}
+
+func (fs *fileService) emit(t entity.EventType, name string, info entity.UploadInfo) {
+	if !fs.localEvents() {
+		return
+	}
+	fs.events.Publish(events.SourceLocal, entity.FileEvent{
+		Type:      t,
+		Name:      name,
+		Size:      info.Size,
+		ETag:      info.ETag,
+		VersionID: info.VersionID,
+	})
+}
+
+func (fs *fileService) WatchFiles(ctx context.Context, prefix, cursor string, send func(entity.FileEvent) error) error {
+	l := logger.FromContext(ctx)
+	l.Infow("service.WatchFiles called", "prefix", prefix, "cursor", cursor)
+	if fs.events == nil {
+		l.Error("file events are not enabled")
+		return fmt.Errorf("%w: file events are not enabled", ErrFeatureDisabled)
+	}
+
+	sub, err := fs.events.Subscribe(cursor, prefix)
+	if err != nil {
+		l.Warnw("failed to subscribe to file events", "error", err, "cursor", cursor)
+		return fmt.Errorf("failed to subscribe to file events: %w", err)
+	}
+	defer sub.Close()
+
+	sent := 0
+	for {
+		select {
+		case <-ctx.Done():
+			l.Infow("service.WatchFiles finished", "prefix", prefix, "events", sent)
+			return ctx.Err()
+		case e, ok := <-sub.Events():
+			if !ok {
+				l.Warnw("file event subscription ended", "error", sub.Err(), "events", sent)
+				return fmt.Errorf("file event subscription ended: %w", sub.Err())
+			}
+			if err := send(e); err != nil {
+				l.Errorw("failed to send file event", "error", err)
+				return fmt.Errorf("failed to send file event: %w", err)
+			}
+			sent++
+		}
+	}
+}
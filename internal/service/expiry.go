@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"time"
+)
+
+func (fs *fileService) ReapExpired(ctx context.Context, batchSize int) ([]string, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.ReapExpired called", "batch_size", batchSize)
+	if err := fs.maintenanceLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire maintenance semaphore", "error", err)
+		return nil, fmt.Errorf("failed to acquire maintenance semaphore: %w", err)
+	}
+	defer fs.maintenanceLimiter.Release(1)
+
+	reaped, err := fs.storage.ReapExpired(ctx, time.Now(), batchSize)
+	for _, name := range reaped {
+		fs.emit(entity.EventDeleted, name, entity.UploadInfo{})
+	}
+	// Renditions of files replaced or deleted by any means, not only by
+	// expiry, are left behind; the reaper clears them too.
+	if swept, serr := fs.storage.SweepRenditions(ctx, batchSize); serr != nil {
+		l.Warnw("failed to sweep stale renditions", "error", serr, "removed", swept)
+	} else if swept > 0 {
+		l.Infow("stale renditions removed", "count", swept)
+	}
+	if err != nil {
+		l.Errorw("storage error reap expired", "error", err, "reaped", len(reaped))
+		return reaped, fmt.Errorf("storage error reap expired: %w", err)
+	}
+	l.Infow("service.ReapExpired finished", "reaped", len(reaped))
+	return reaped, nil
+}
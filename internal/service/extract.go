@@ -0,0 +1,210 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	DefaultExtractMaxEntrySize = 64 * 1024 * 1024
+	DefaultExtractMaxEntries   = 10000
+	DefaultExtractMaxZipSize   = 1024 * 1024 * 1024
+)
+
+var errEntryTooLarge = errors.New("entry exceeds size limit")
+
+// ExtractArchive unpacks a ZIP or TAR upload, storing every regular file as
+// its own object under prefix. Problems with single entries are reported in
+// the result and do not stop the extraction; an unreadable archive stops it
+// and is reported as an entry error for the archive itself.
+func (fs *fileService) ExtractArchive(ctx context.Context, filename string, reader io.Reader, prefix string, opts entity.UploadOptions) (entity.ExtractResult, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.ExtractArchive called", "filename", filename, "prefix", prefix)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.ExtractResult{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	format, ok := archiveFormatOf(filename)
+	if !ok {
+		l.Errorw("unsupported archive type", "filename", filename)
+		return entity.ExtractResult{}, fmt.Errorf("%w: %s is not a .zip, .tar, .tar.gz or .tgz archive", ErrInvalidArgument, filename)
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" {
+		if err := validateFilename(ctx, prefix); err != nil {
+			return entity.ExtractResult{}, err
+		}
+	}
+	if opts.IfMatch != "" {
+		l.Error("if_match is not supported for archive extraction")
+		return entity.ExtractResult{}, fmt.Errorf("%w: if_match is not supported for archive extraction", ErrInvalidArgument)
+	}
+	if err := validateUploadOptions(ctx, opts); err != nil {
+		return entity.ExtractResult{}, err
+	}
+
+	x := &extractor{fs: fs, prefix: prefix, opts: opts}
+	var err error
+	switch format {
+	case ArchiveZip:
+		err = x.extractZip(ctx, reader)
+	case ArchiveTarGz:
+		gz, gzErr := gzip.NewReader(reader)
+		if gzErr != nil {
+			err = gzErr
+			break
+		}
+		err = x.extractTar(ctx, gz)
+	default:
+		err = x.extractTar(ctx, reader)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return x.result, ctxErr
+	}
+	if errors.Is(err, ErrInvalidArgument) {
+		l.Errorw("archive rejected", "filename", filename, "error", err)
+		return x.result, err
+	}
+	if err != nil {
+		l.Errorw("archive extraction stopped", "filename", filename, "error", err)
+		x.fail(filename, err)
+	}
+	l.Infow("service.ExtractArchive finished", "filename", filename, "created", len(x.result.Created), "failed", len(x.result.Failed))
+	return x.result, nil
+}
+
+type extractor struct {
+	fs      *fileService
+	prefix  string
+	opts    entity.UploadOptions
+	entries int
+	result  entity.ExtractResult
+}
+
+func (x *extractor) fail(name string, err error) {
+	x.result.Failed = append(x.result.Failed, entity.EntryError{Name: name, Error: err.Error()})
+}
+
+// entry stores one archive member. It returns an error only when extraction
+// must stop.
+func (x *extractor) entry(ctx context.Context, name string, size int64, r io.Reader) error {
+	x.entries++
+	if x.entries > x.fs.extractMaxEntries {
+		return fmt.Errorf("archive has more than %d entries", x.fs.extractMaxEntries)
+	}
+	key := strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./")
+	if x.prefix != "" {
+		key = x.prefix + "/" + key
+	}
+	if !isCanonicalName(key) {
+		x.fail(name, fmt.Errorf("%w: unsafe entry name", ErrInvalidArgument))
+		return nil
+	}
+	if size > x.fs.extractMaxEntrySize {
+		x.fail(name, errEntryTooLarge)
+		return nil
+	}
+
+	info, err := x.fs.saveFile(ctx, key, &entryLimitReader{r: r, limit: x.fs.extractMaxEntrySize}, x.opts)
+	if err != nil {
+		logger.FromContext(ctx).Warnw("failed to store archive entry", "entry", name, "key", key, "error", err)
+		x.fail(name, err)
+		return ctx.Err()
+	}
+	x.result.Created = append(x.result.Created, info)
+	return nil
+}
+
+func (x *extractor) extractTar(ctx context.Context, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeReg:
+			if err := x.entry(ctx, hdr.Name, hdr.Size, tr); err != nil {
+				return err
+			}
+		default:
+			x.fail(hdr.Name, fmt.Errorf("%w: unsupported entry type", ErrInvalidArgument))
+		}
+	}
+}
+
+// extractZip spools the upload to a temp file first: the ZIP central
+// directory sits at the end of the archive.
+func (x *extractor) extractZip(ctx context.Context, r io.Reader) error {
+	f, err := os.CreateTemp("", "extract-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()); _ = f.Close() }()
+	limit := x.fs.extractMaxZipSize
+	size, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if size > limit {
+		return fmt.Errorf("%w: zip archive larger than %d bytes", ErrInvalidArgument, limit)
+	}
+
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		if !zf.Mode().IsRegular() {
+			x.fail(zf.Name, fmt.Errorf("%w: unsupported entry type", ErrInvalidArgument))
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			x.fail(zf.Name, err)
+			continue
+		}
+		err = x.entry(ctx, zf.Name, int64(zf.UncompressedSize64), rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryLimitReader fails once more than limit bytes have been read, so that
+// entries lying about their size cannot be stored.
+type entryLimitReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (e *entryLimitReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	e.read += int64(n)
+	if e.read > e.limit {
+		return n, errEntryTooLarge
+	}
+	return n, err
+}
@@ -7,22 +7,42 @@ import (
 	"github.com/PianyCoder/test_file_service/internal/storage"
 
 	entity "github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
 	"golang.org/x/sync/semaphore"
 	"io"
-	"path/filepath"
 )
 
-const DefaultChunkSize = 1024 * 1024
+const (
+	DefaultChunkSize = 1024 * 1024
+	MinChunkSize     = 4 * 1024
+	// DefaultMaxChunkSize keeps a chunk and its framing under the 4 MiB
+	// default gRPC message limit of clients.
+	DefaultMaxChunkSize = 2 * 1024 * 1024
+)
 
 type fileService struct {
 	storage         storage.FileStorage
 	chunkSize       int
+	maxChunkSize    int
 	uploadLimiter   *semaphore.Weighted
 	downloadLimiter *semaphore.Weighted
 	listLimiter     *semaphore.Weighted
+	imageLimits     imaging.Limits
+	autoCompression string
+
+	// maintenanceLimiter runs one background scan at a time without taking
+	// the slots of client requests.
+	maintenanceLimiter *semaphore.Weighted
+
+	extractMaxEntrySize int64
+	extractMaxEntries   int
+	extractMaxZipSize   int64
+
+	events *events.Bus
 }
 
-func NewFileService(storage storage.FileStorage, uploadLimit, downloadLimit, listLimit int64, chunkSize int) FileService {
+func NewFileService(storage storage.FileStorage, uploadLimit, downloadLimit, listLimit int64, chunkSize int, opts ...Option) FileService {
 	if chunkSize <= 0 {
 		chunkSize = DefaultChunkSize
 	}
@@ -37,74 +57,80 @@ func NewFileService(storage storage.FileStorage, uploadLimit, downloadLimit, lis
 		listLimit = 100
 	}
 
-	return &fileService{
+	fs := &fileService{
 		storage:         storage,
 		chunkSize:       chunkSize,
+		maxChunkSize:    DefaultMaxChunkSize,
 		uploadLimiter:   semaphore.NewWeighted(uploadLimit),
 		downloadLimiter: semaphore.NewWeighted(downloadLimit),
 		listLimiter:     semaphore.NewWeighted(listLimit),
+
+		maintenanceLimiter: semaphore.NewWeighted(1),
+		imageLimits:        imaging.DefaultLimits,
+
+		extractMaxEntrySize: DefaultExtractMaxEntrySize,
+		extractMaxEntries:   DefaultExtractMaxEntries,
+		extractMaxZipSize:   DefaultExtractMaxZipSize,
 	}
+	for _, opt := range opts {
+		opt(fs)
+	}
+	fs.chunkSize = min(max(fs.chunkSize, MinChunkSize), fs.maxChunkSize)
+	return fs
 }
 
-func (fs *fileService) UploadFile(ctx context.Context, filename string, reader io.Reader) error {
+func (fs *fileService) UploadFile(ctx context.Context, filename string, reader io.Reader, opts entity.UploadOptions) (entity.UploadInfo, error) {
 	l := logger.FromContext(ctx)
 	l.Infow("service.UploadFile called", "filename", filename)
 	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
 		l.Errorw("failed to acquire upload semaphore", "error", err)
-		return fmt.Errorf("failed to acquire upload semaphore: %w", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
 	}
 	defer fs.uploadLimiter.Release(1)
 
-	if filename == "" {
-		l.Error("filename cannot be empty")
-		return fmt.Errorf("filename cannot be empty")
-	}
-
-	if filepath.Clean(filename) != filepath.Base(filename) {
-		l.Errorw("invalid filename (possible traversal)", "filename", filename)
-		return fmt.Errorf("invalid filename (possible traversal): %s", filename)
+	if err := validateUploadOptions(ctx, opts); err != nil {
+		return entity.UploadInfo{}, err
 	}
 
-	if err := fs.storage.SaveFile(ctx, filename, reader, -1); err != nil {
-		l.Errorw("storage error on save", "error", err, "filename", filename)
-		return fmt.Errorf("storage error on save: %w", err)
+	info, err := fs.saveFile(ctx, filename, reader, opts)
+	if err != nil {
+		return entity.UploadInfo{}, err
 	}
-	l.Infow("service.UploadFile finished", "filename", filename)
-	return nil
+	l.Infow("service.UploadFile finished", "filename", filename, "version_id", info.VersionID)
+	return info, nil
 }
 
-func (fs *fileService) DownloadFile(ctx context.Context, filename string, writer io.Writer) error {
+// saveFile validates the name and stores a single file, applying the
+// compression policy and image processing.
+func (fs *fileService) saveFile(ctx context.Context, filename string, reader io.Reader, opts entity.UploadOptions) (entity.UploadInfo, error) {
 	l := logger.FromContext(ctx)
-	l.Infow("service.DownloadFile called", "filename", filename)
-	if err := fs.downloadLimiter.Acquire(ctx, 1); err != nil {
-		l.Errorw("failed to acquire download semaphore", "error", err)
-		return fmt.Errorf("failed to acquire download semaphore: %w", err)
+	if err := validateFilename(ctx, filename); err != nil {
+		return entity.UploadInfo{}, err
 	}
-	defer fs.downloadLimiter.Release(1)
 
-	if filename == "" {
-		l.Error("filename cannot be empty")
-		return fmt.Errorf("filename cannot be empty")
-	}
-	if filepath.Clean(filename) != filepath.Base(filename) {
-		l.Errorw("invalid filename (possible traversal)", "filename", filename)
-		return fmt.Errorf("invalid filename (possible traversal): %s", filename)
+	compression, err := fs.resolveCompression(filename, opts.Compression)
+	if err != nil {
+		l.Errorw("invalid compression", "error", err, "filename", filename)
+		return entity.UploadInfo{}, err
 	}
+	opts.Compression = compression
 
-	r, err := fs.storage.GetFileReader(ctx, filename)
+	reader, err = fs.prepareImage(ctx, filename, reader, &opts)
 	if err != nil {
-		l.Errorw("storage error on get", "error", err, "filename", filename)
-		return fmt.Errorf("storage error on get: %w", err)
+		return entity.UploadInfo{}, err
 	}
-	defer r.Close()
 
-	n, err := io.Copy(writer, r)
+	eventType := entity.EventCreated
+	if fs.localEvents() {
+		eventType = fs.writeEventType(ctx, filename)
+	}
+	info, err := fs.storage.SaveFile(ctx, filename, reader, -1, opts)
 	if err != nil {
-		l.Errorw("failed to stream file to writer", "error", err, "bytes_copied", n, "filename", filename)
-		return fmt.Errorf("failed to stream file to writer: %w", err)
+		l.Errorw("storage error on save", "error", err, "filename", filename)
+		return entity.UploadInfo{}, fmt.Errorf("storage error on save: %w", err)
 	}
-	l.Infow("service.DownloadFile finished", "filename", filename, "bytes", n)
-	return nil
+	fs.emit(eventType, filename, info)
+	return info, nil
 }
 
 func (fs *fileService) ListFiles(ctx context.Context) ([]entity.FileMetadata, error) {
@@ -124,3 +150,52 @@ func (fs *fileService) ListFiles(ctx context.Context) ([]entity.FileMetadata, er
 	l.Infow("service.ListFiles finished", "count", len(metadata))
 	return metadata, nil
 }
+
+func (fs *fileService) ListFileVersions(ctx context.Context, filename string) ([]entity.FileVersion, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.ListFileVersions called", "filename", filename)
+	if err := fs.listLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire list semaphore", "error", err)
+		return nil, fmt.Errorf("failed to acquire list semaphore: %w", err)
+	}
+	defer fs.listLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return nil, err
+	}
+
+	versions, err := fs.storage.ListFileVersions(ctx, filename)
+	if err != nil {
+		l.Errorw("storage error list versions", "error", err, "filename", filename)
+		return nil, fmt.Errorf("storage error list versions: %w", err)
+	}
+	l.Infow("service.ListFileVersions finished", "filename", filename, "count", len(versions))
+	return versions, nil
+}
+
+func (fs *fileService) RestoreFileVersion(ctx context.Context, filename, versionID string) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.RestoreFileVersion called", "filename", filename, "version_id", versionID)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return entity.UploadInfo{}, err
+	}
+	if versionID == "" {
+		l.Error("version id cannot be empty")
+		return entity.UploadInfo{}, fmt.Errorf("%w: version id cannot be empty", ErrInvalidArgument)
+	}
+
+	info, err := fs.storage.RestoreFileVersion(ctx, filename, versionID)
+	if err != nil {
+		l.Errorw("storage error restore version", "error", err, "filename", filename, "version_id", versionID)
+		return entity.UploadInfo{}, fmt.Errorf("storage error restore version: %w", err)
+	}
+	fs.emit(entity.EventUpdated, filename, info)
+	l.Infow("service.RestoreFileVersion finished", "filename", filename, "version_id", info.VersionID)
+	return info, nil
+}
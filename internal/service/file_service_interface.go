@@ -3,11 +3,35 @@ package service
 import (
 	"context"
 	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
 	"io"
+	"time"
 )
 
 type FileService interface {
-	UploadFile(ctx context.Context, filename string, reader io.Reader) error
-	DownloadFile(ctx context.Context, filename string, writer io.Writer) error
+	UploadFile(ctx context.Context, filename string, reader io.Reader, opts entity.UploadOptions) (entity.UploadInfo, error)
+	ExtractArchive(ctx context.Context, filename string, reader io.Reader, prefix string, opts entity.UploadOptions) (entity.ExtractResult, error)
+	DownloadFile(ctx context.Context, filename string, opts entity.ReadOptions, writer io.Writer) error
+	// DownloadChunks reads the file into chunks of chunkSize bytes, 0 for the
+	// configured size, and passes each to send. The chunk is reused once send
+	// returns.
+	DownloadChunks(ctx context.Context, filename string, opts entity.ReadOptions, chunkSize int, send func([]byte) error) error
 	ListFiles(ctx context.Context) ([]entity.FileMetadata, error)
+	StatFile(ctx context.Context, filename string) (entity.FileMetadata, error)
+	SetAttributes(ctx context.Context, filename string, attrs map[string]string) (entity.FileMetadata, error)
+	ListFileVersions(ctx context.Context, filename string) ([]entity.FileVersion, error)
+	RestoreFileVersion(ctx context.Context, filename, versionID string) (entity.UploadInfo, error)
+	CopyFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error)
+	MoveFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error)
+	// DeleteFile moves the file to the trash; with a non-empty ifMatch only
+	// if the file has that ETag.
+	DeleteFile(ctx context.Context, filename, ifMatch string) (entity.TrashItem, error)
+	ListTrash(ctx context.Context) ([]entity.TrashItem, error)
+	RestoreFromTrash(ctx context.Context, id string, overwrite bool) (entity.UploadInfo, error)
+	EmptyTrash(ctx context.Context) (int, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int, error)
+	ReapExpired(ctx context.Context, batchSize int) ([]string, error)
+	GetImageRendition(ctx context.Context, filename string, opts imaging.Options, writer io.Writer) error
+	WatchFiles(ctx context.Context, prefix, cursor string, send func(entity.FileEvent) error) error
+	DownloadArchive(ctx context.Context, filenames []string, prefix string, format ArchiveFormat, writer io.Writer) error
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+	"io"
+)
+
+// prepareImage extracts image info from JPEG and PNG uploads and applies the
+// requested metadata stripping and auto-orientation.
+func (fs *fileService) prepareImage(ctx context.Context, filename string, reader io.Reader, opts *entity.UploadOptions) (io.Reader, error) {
+	l := logger.FromContext(ctx)
+	r, info, err := imaging.Inspect(reader, opts.StripMetadata)
+	if err != nil {
+		l.Errorw("failed to strip image metadata", "filename", filename, "error", err)
+		return nil, fmt.Errorf("%w: cannot strip metadata: %v", ErrInvalidArgument, err)
+	}
+	if info == nil {
+		if opts.StripMetadata || opts.AutoOrient {
+			l.Debugw("upload is not a jpeg or png image; image options ignored", "filename", filename)
+		}
+		return r, nil
+	}
+	l.Infow("image upload inspected", "filename", filename, "format", info.Format, "width", info.Width, "height", info.Height, "orientation", info.Orientation, "stripped", opts.StripMetadata)
+
+	if opts.AutoOrient && info.Orientation > 1 {
+		data, err := imaging.AutoOrient(r, info, fs.imageLimits)
+		if err != nil {
+			l.Errorw("failed to auto-orient image", "filename", filename, "error", err)
+			return nil, fmt.Errorf("failed to auto-orient image: %w", err)
+		}
+		l.Infow("image auto-oriented", "filename", filename, "width", info.Width, "height", info.Height)
+		r = bytes.NewReader(data)
+	}
+	opts.ImageInfo = info
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/PianyCoder/test_file_service/internal/events"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+)
+
+type Option func(*fileService)
+
+func WithImageLimits(limits imaging.Limits) Option {
+	return func(fs *fileService) {
+		fs.imageLimits = limits
+	}
+}
+
+// WithAutoCompression compresses uploads with a text-like content type using
+// the given algorithm unless the client chooses otherwise.
+func WithAutoCompression(algorithm string) Option {
+	return func(fs *fileService) {
+		fs.autoCompression = algorithm
+	}
+}
+
+// WithExtractLimits bounds archive extraction. maxZipSize bounds a ZIP
+// upload as a whole, since it is spooled to disk before extraction.
+func WithExtractLimits(maxEntrySize int64, maxEntries int, maxZipSize int64) Option {
+	return func(fs *fileService) {
+		if maxEntrySize > 0 {
+			fs.extractMaxEntrySize = maxEntrySize
+		}
+		if maxEntries > 0 {
+			fs.extractMaxEntries = maxEntries
+		}
+		if maxZipSize > 0 {
+			fs.extractMaxZipSize = maxZipSize
+		}
+	}
+}
+
+// WithEvents publishes file changes made through the service to bus while
+// the bus takes its events from the in-process source.
+func WithEvents(bus *events.Bus) Option {
+	return func(fs *fileService) {
+		fs.events = bus
+	}
+}
+
+// WithMaxChunkSize bounds the download chunk size clients may ask for; the
+// configured chunk size is lowered to it if larger.
+func WithMaxChunkSize(n int) Option {
+	return func(fs *fileService) {
+		if n > 0 {
+			fs.maxChunkSize = max(n, MinChunkSize)
+		}
+	}
+}
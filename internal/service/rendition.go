@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/imaging"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"io"
+	"strings"
+)
+
+// renditionKey includes the source ETag so that overwriting the source
+// naturally invalidates every cached rendition of it; the expiry reaper
+// removes the invalidated ones, see storage.SweepRenditions.
+func renditionKey(filename, etag string, opts imaging.Options) string {
+	return fmt.Sprintf("%s%s/%s/%dx%d-%s.%s", storage.RenditionPrefix, filename, strings.Trim(etag, `"`), opts.Width, opts.Height, opts.Fit, opts.Format)
+}
+
+func (fs *fileService) GetImageRendition(ctx context.Context, filename string, opts imaging.Options, writer io.Writer) error {
+	l := logger.FromContext(ctx)
+	l.Infow("service.GetImageRendition called", "filename", filename, "width", opts.Width, "height", opts.Height, "fit", opts.Fit, "format", opts.Format)
+	if err := fs.downloadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire download semaphore", "error", err)
+		return fmt.Errorf("failed to acquire download semaphore: %w", err)
+	}
+	defer fs.downloadLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return err
+	}
+	if err := opts.Validate(fs.imageLimits); err != nil {
+		l.Errorw("invalid rendition options", "error", err)
+		return fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+
+	meta, err := fs.storage.StatFile(ctx, filename)
+	if err != nil {
+		l.Errorw("storage error on stat", "error", err, "filename", filename)
+		return fmt.Errorf("storage error on stat: %w", err)
+	}
+	key := renditionKey(filename, meta.ETag, opts)
+
+	cached, err := fs.storage.GetFileReader(ctx, key, entity.ReadOptions{})
+	switch {
+	case err == nil:
+		defer cached.Close()
+		n, err := io.Copy(writer, cached)
+		if err != nil {
+			l.Errorw("failed to stream cached rendition", "error", err, "key", key)
+			return fmt.Errorf("failed to stream cached rendition: %w", err)
+		}
+		l.Infow("service.GetImageRendition served from cache", "filename", filename, "key", key, "bytes", n)
+		return nil
+	case !errors.Is(err, storage.ErrNotFound):
+		l.Warnw("failed to read cached rendition, rendering again", "error", err, "key", key)
+	}
+
+	// The rendition is cached under meta.ETag, so it must be rendered from
+	// exactly that content.
+	src, err := fs.storage.GetFileReader(ctx, filename, entity.ReadOptions{IfMatch: meta.ETag})
+	if errors.Is(err, storage.ErrPreconditionFailed) {
+		l.Warnw("file changed while rendering", "filename", filename)
+		return fmt.Errorf("%w: file changed while rendering: %s", storage.ErrPreconditionFailed, filename)
+	}
+	if err != nil {
+		l.Errorw("storage error on get", "error", err, "filename", filename)
+		return fmt.Errorf("storage error on get: %w", err)
+	}
+	defer src.Close()
+
+	var buf bytes.Buffer
+	if err := imaging.Render(src, &buf, opts, fs.imageLimits); err != nil {
+		l.Errorw("failed to render image", "error", err, "filename", filename)
+		return fmt.Errorf("failed to render image: %w", err)
+	}
+	size := int64(buf.Len())
+	if _, err := fs.storage.SaveFile(ctx, key, bytes.NewReader(buf.Bytes()), size, entity.UploadOptions{}); err != nil {
+		l.Warnw("failed to cache rendition", "error", err, "key", key)
+	}
+
+	if _, err := buf.WriteTo(writer); err != nil {
+		l.Errorw("failed to stream rendition", "error", err, "filename", filename)
+		return fmt.Errorf("failed to stream rendition: %w", err)
+	}
+	l.Infow("service.GetImageRendition finished", "filename", filename, "key", key, "bytes", size)
+	return nil
+}
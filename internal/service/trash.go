@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"strings"
+	"time"
+)
+
+func (fs *fileService) DeleteFile(ctx context.Context, filename, ifMatch string) (entity.TrashItem, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.DeleteFile called", "filename", filename, "if_match", ifMatch)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.TrashItem{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if err := validateFilename(ctx, filename); err != nil {
+		return entity.TrashItem{}, err
+	}
+
+	item, err := fs.storage.MoveToTrash(ctx, filename, ifMatch)
+	if err != nil {
+		l.Errorw("storage error on delete", "error", err, "filename", filename)
+		return entity.TrashItem{}, fmt.Errorf("storage error on delete: %w", err)
+	}
+	fs.emit(entity.EventDeleted, filename, entity.UploadInfo{Size: item.Size})
+	l.Infow("service.DeleteFile finished", "filename", filename, "trash_id", item.ID)
+	return item, nil
+}
+
+func (fs *fileService) ListTrash(ctx context.Context) ([]entity.TrashItem, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.ListTrash called")
+	if err := fs.listLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire list semaphore", "error", err)
+		return nil, fmt.Errorf("failed to acquire list semaphore: %w", err)
+	}
+	defer fs.listLimiter.Release(1)
+
+	items, err := fs.storage.ListTrash(ctx)
+	if err != nil {
+		l.Errorw("storage error list trash", "error", err)
+		return nil, fmt.Errorf("storage error list trash: %w", err)
+	}
+	l.Infow("service.ListTrash finished", "count", len(items))
+	return items, nil
+}
+
+func (fs *fileService) RestoreFromTrash(ctx context.Context, id string, overwrite bool) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.RestoreFromTrash called", "trash_id", id, "overwrite", overwrite)
+	if err := fs.uploadLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire upload semaphore", "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to acquire upload semaphore: %w", err)
+	}
+	defer fs.uploadLimiter.Release(1)
+
+	if id == "" {
+		l.Error("trash id cannot be empty")
+		return entity.UploadInfo{}, fmt.Errorf("%w: trash id cannot be empty", ErrInvalidArgument)
+	}
+
+	eventType := entity.EventCreated
+	if overwrite && fs.localEvents() {
+		// Trash ids are "<deleted-at>/<original name>".
+		if _, name, ok := strings.Cut(id, "/"); ok {
+			eventType = fs.writeEventType(ctx, name)
+		}
+	}
+	info, err := fs.storage.RestoreFromTrash(ctx, id, overwrite)
+	if err != nil {
+		l.Errorw("storage error restore from trash", "error", err, "trash_id", id)
+		return entity.UploadInfo{}, fmt.Errorf("storage error restore from trash: %w", err)
+	}
+	fs.emit(eventType, info.Name, info)
+	l.Infow("service.RestoreFromTrash finished", "trash_id", id, "filename", info.Name)
+	return info, nil
+}
+
+func (fs *fileService) EmptyTrash(ctx context.Context) (int, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.EmptyTrash called")
+	return fs.purgeTrash(ctx, time.Now())
+}
+
+func (fs *fileService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("service.PurgeTrash called", "retention", retention)
+	return fs.purgeTrash(ctx, time.Now().Add(-retention))
+}
+
+func (fs *fileService) purgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	l := logger.FromContext(ctx)
+	if err := fs.maintenanceLimiter.Acquire(ctx, 1); err != nil {
+		l.Errorw("failed to acquire maintenance semaphore", "error", err)
+		return 0, fmt.Errorf("failed to acquire maintenance semaphore: %w", err)
+	}
+	defer fs.maintenanceLimiter.Release(1)
+
+	n, err := fs.storage.PurgeTrash(ctx, deletedBefore)
+	if err != nil {
+		l.Errorw("storage error purge trash", "error", err, "purged", n)
+		return n, fmt.Errorf("storage error purge trash: %w", err)
+	}
+	l.Infow("trash purged", "purged", n, "deleted_before", deletedBefore)
+	return n, nil
+}
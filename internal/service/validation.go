@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/PianyCoder/test_file_service/internal/storage"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrInvalidArgument = errors.New("invalid argument")
+
+func validateFilename(ctx context.Context, filename string) error {
+	l := logger.FromContext(ctx)
+	if filename == "" {
+		l.Error("filename cannot be empty")
+		return fmt.Errorf("%w: filename cannot be empty", ErrInvalidArgument)
+	}
+	if !isCanonicalName(filename) {
+		l.Errorw("invalid filename (possible traversal)", "filename", filename)
+		return fmt.Errorf("%w: invalid filename (possible traversal): %s", ErrInvalidArgument, filename)
+	}
+	if storage.IsReserved(filename) {
+		l.Errorw("filename uses a reserved prefix", "filename", filename)
+		return fmt.Errorf("%w: reserved filename: %s", ErrInvalidArgument, filename)
+	}
+	return nil
+}
+
+// isCanonicalName allows nested names such as "photos/2024/a.jpg", but only
+// in their canonical form: no leading slash and no empty, "." or ".." segments.
+// Nested names are valid for every call, not only for archive extraction:
+// extracted files must be readable and deletable by name like any other.
+func isCanonicalName(name string) bool {
+	if path.IsAbs(name) || path.Clean(name) != name || strings.ContainsAny(name, "\\\x00") {
+		return false
+	}
+	return name != "." && name != ".." && !strings.HasPrefix(name, "../")
+}
+
+func validateUploadOptions(ctx context.Context, opts entity.UploadOptions) error {
+	l := logger.FromContext(ctx)
+	if opts.IfNoneMatch != "" && opts.IfNoneMatch != "*" {
+		l.Errorw("unsupported if_none_match value", "if_none_match", opts.IfNoneMatch)
+		return fmt.Errorf("%w: if_none_match only supports \"*\"", ErrInvalidArgument)
+	}
+	if opts.IfNoneMatch != "" && opts.IfMatch != "" {
+		l.Error("if_none_match and if_match are mutually exclusive")
+		return fmt.Errorf("%w: if_none_match and if_match are mutually exclusive", ErrInvalidArgument)
+	}
+	if !opts.ExpiresAt.IsZero() && !opts.ExpiresAt.After(time.Now()) {
+		l.Errorw("expiration time is in the past", "expires_at", opts.ExpiresAt)
+		return fmt.Errorf("%w: expiration time must be in the future", ErrInvalidArgument)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestValidateFilename(t *testing.T) {
+	ctx := logger.WithContext(context.Background(), zap.NewNop())
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"a.txt", true},
+		{"photos/2024/a.jpg", true},
+		{"..a", true},
+		{"a/..b", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"/a", false},
+		{"../a", false},
+		{"./a", false},
+		{"a/../b", false},
+		{"a/./b", false},
+		{"a//b", false},
+		{"a/", false},
+		{"a\\b", false},
+		{"a\x00b", false},
+		{".trash/a", false},
+		{".blobs/a", false},
+		{".renditions/a", false},
+	}
+	for _, tt := range tests {
+		err := validateFilename(ctx, tt.name)
+		if tt.valid && err != nil {
+			t.Errorf("validateFilename(%q) = %v, want nil", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("validateFilename(%q) = %v, want ErrInvalidArgument", tt.name, err)
+		}
+	}
+}
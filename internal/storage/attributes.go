@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/minio/minio-go/v7"
+	"strings"
+)
+
+// Attributes are free-form key/value pairs set by clients. They are stored as
+// Fs-Attr-<key> user metadata with lower-case keys, since S3 canonicalizes
+// the case of metadata headers.
+const metaAttrPrefix = "Fs-Attr-"
+
+func attributes(meta map[string]string) map[string]string {
+	var attrs map[string]string
+	for k, v := range meta {
+		k = strings.TrimPrefix(k, "X-Amz-Meta-")
+		if len(k) <= len(metaAttrPrefix) || !strings.EqualFold(k[:len(metaAttrPrefix)], metaAttrPrefix) {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[strings.ToLower(k[len(metaAttrPrefix):])] = v
+	}
+	return attrs
+}
+
+// SetAttributes merges attrs into the object's attributes; an empty value
+// removes the attribute. The object is copied onto itself, so its content and
+// remaining metadata are kept.
+func (ms *MinioStorage) SetAttributes(ctx context.Context, filename string, attrs map[string]string) (entity.FileMetadata, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("SetAttributes called", "bucket", ms.bucket, "object", filename, "attributes", len(attrs))
+	info, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return entity.FileMetadata{}, fmt.Errorf("%w: %s", ErrNotFound, filename)
+		}
+		l.Errorw("failed to stat object", "object", filename, "error", err)
+		return entity.FileMetadata{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	meta := make(map[string]string, len(info.UserMetadata)+len(attrs))
+	for k, v := range copyMetadata(info.UserMetadata) {
+		if !strings.HasPrefix(strings.ToLower(k), strings.ToLower(metaAttrPrefix)) {
+			meta[k] = v
+		}
+	}
+	merged := attributes(info.UserMetadata)
+	if merged == nil {
+		merged = make(map[string]string, len(attrs))
+	}
+	for k, v := range attrs {
+		if v == "" {
+			delete(merged, strings.ToLower(k))
+			continue
+		}
+		merged[strings.ToLower(k)] = v
+	}
+	for k, v := range merged {
+		meta[metaAttrPrefix+k] = v
+	}
+
+	dstOpts := minio.CopyDestOptions{Bucket: ms.bucket, Object: filename, UserMetadata: meta, ReplaceMetadata: true}
+	srcOpts := minio.CopySrcOptions{Bucket: ms.bucket, Object: filename, VersionID: info.VersionID, MatchETag: info.ETag}
+	if info.Size > maxCopySize {
+		_, err = ms.client.ComposeObject(ctx, dstOpts, srcOpts)
+	} else {
+		_, err = ms.client.CopyObject(ctx, dstOpts, srcOpts)
+	}
+	if err != nil {
+		if isPreconditionFailed(err) {
+			return entity.FileMetadata{}, fmt.Errorf("%w: %s changed while updating attributes", ErrPreconditionFailed, filename)
+		}
+		l.Errorw("failed to update object metadata", "object", filename, "error", err)
+		return entity.FileMetadata{}, fmt.Errorf("failed to update object metadata: %w", err)
+	}
+	l.Infow("object attributes updated", "object", filename, "attributes", len(merged))
+	return ms.StatFile(ctx, filename)
+}
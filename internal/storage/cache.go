@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/infrastructure/metrics"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	cacheHits      = metrics.Counter("storage_cache_hits_total")
+	cacheMisses    = metrics.Counter("storage_cache_misses_total")
+	cacheFills     = metrics.Counter("storage_cache_fills_total")
+	cacheEvictions = metrics.Counter("storage_cache_evictions_total")
+)
+
+var errCacheStale = errors.New("file changed while it was cached")
+
+type CacheOptions struct {
+	Dir                 string // directory of the disk tier, emptied on start
+	MaxBytes            int64  // capacity of the disk tier
+	MaxObjectSize       int64  // larger files are read through uncached
+	MemoryMaxBytes      int64  // capacity of the memory tier
+	MemoryMaxObjectSize int64  // files up to this size are cached in memory instead of on disk
+}
+
+// cachedStorage is a read-through cache of whole files in front of a
+// FileStorage. Entries are valid for the ETag they were read at: every read
+// still stats the file, and a file overwritten since it was cached is a miss.
+// Stale entries are not removed eagerly but age out of the LRU.
+type cachedStorage struct {
+	FileStorage
+
+	dir              string
+	maxObjectSize    int64
+	memMaxObjectSize int64
+
+	mu    sync.Mutex
+	disk  *cacheLRU
+	mem   *cacheLRU
+	fills singleflight.Group
+}
+
+type cacheEntry struct {
+	name string
+	etag string
+	size int64
+	data []byte // content of a memory entry
+	path string // file of a disk entry
+
+	checked time.Time // when etag was last confirmed to be current
+}
+
+// NewCachedStorage returns next with reads of the latest version of files up
+// to opts.MaxObjectSize served from a local cache. Concurrent misses for the
+// same file share a single read from next. Reads of versions, raw reads and
+// larger files go to next directly.
+func NewCachedStorage(next FileStorage, opts CacheOptions) (FileStorage, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	// The index is not persisted, so files left by a previous run are unknown.
+	for _, pattern := range []string{"*.cache", "*.tmp"} {
+		stale, err := filepath.Glob(filepath.Join(opts.Dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cache dir: %w", err)
+		}
+		for _, path := range stale {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to clear cache dir: %w", err)
+			}
+		}
+	}
+
+	cs := &cachedStorage{
+		FileStorage:      next,
+		dir:              opts.Dir,
+		maxObjectSize:    min(opts.MaxObjectSize, opts.MaxBytes),
+		memMaxObjectSize: min(opts.MemoryMaxObjectSize, opts.MemoryMaxBytes),
+	}
+	cs.disk = newCacheLRU(opts.MaxBytes, func(e *cacheEntry) {
+		cacheEvictions.Add(1)
+		_ = os.Remove(e.path)
+	})
+	cs.mem = newCacheLRU(opts.MemoryMaxBytes, func(*cacheEntry) {
+		cacheEvictions.Add(1)
+	})
+	return cs, nil
+}
+
+func (cs *cachedStorage) GetFileReader(ctx context.Context, filename string, opts entity.ReadOptions) (io.ReadCloser, error) {
+	if opts.VersionID != "" || opts.Raw {
+		return cs.FileStorage.GetFileReader(ctx, filename, opts)
+	}
+	meta, err := cs.FileStorage.StatFile(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReadCondition(filename, meta.ETag, opts); err != nil {
+		return nil, err
+	}
+	if meta.Size > cs.maxObjectSize || opts.Offset < 0 || opts.Offset > meta.Size {
+		return cs.FileStorage.GetFileReader(ctx, filename, opts)
+	}
+
+	if r, ok := cs.open(filename, meta.ETag, opts); ok {
+		cacheHits.Add(1)
+		return r, nil
+	}
+	cacheMisses.Add(1)
+	err = cs.fill(ctx, filename, meta)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warnw("failed to cache file, reading through", "object", filename, "etag", meta.ETag, "error", err)
+	} else if r, ok := cs.open(filename, meta.ETag, opts); ok {
+		return r, nil
+	}
+	return cs.FileStorage.GetFileReader(ctx, filename, opts)
+}
+
+// open returns the requested range of the cached file, if it is cached at
+// etag.
+func (cs *cachedStorage) open(filename, etag string, opts entity.ReadOptions) (io.ReadCloser, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	e := cs.mem.get(filename)
+	if e == nil {
+		e = cs.disk.get(filename)
+	}
+	if e == nil || e.etag != etag {
+		return nil, false
+	}
+
+	end := e.size
+	if opts.Length > 0 && opts.Offset+opts.Length < end {
+		end = opts.Offset + opts.Length
+	}
+	if e.path == "" {
+		return io.NopCloser(bytes.NewReader(e.data[opts.Offset:end])), true
+	}
+	// Opened under the lock, so that it is not evicted in between; an evicted
+	// file stays readable until closed.
+	f, err := os.Open(e.path)
+	if err != nil {
+		cs.disk.remove(filename)
+		return nil, false
+	}
+	return readCloser{Reader: io.NewSectionReader(f, opts.Offset, end-opts.Offset), Closer: f}, true
+}
+
+func (cs *cachedStorage) cached(filename, etag string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, tier := range []*cacheLRU{cs.mem, cs.disk} {
+		if e := tier.peek(filename); e != nil && e.etag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// fill caches filename at the ETag in meta. Callers missing the same version
+// at the same time wait for one read; it goes on when the caller that
+// started it gives up, so that the others are not failed with it.
+func (cs *cachedStorage) fill(ctx context.Context, filename string, meta entity.FileMetadata) error {
+	ch := cs.fills.DoChan(filename+"\x00"+meta.ETag, func() (any, error) {
+		return nil, cs.load(context.WithoutCancel(ctx), filename, meta)
+	})
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (cs *cachedStorage) load(ctx context.Context, filename string, meta entity.FileMetadata) error {
+	// Callers that missed just before the previous fill of this version
+	// ended start another one, which has nothing left to do.
+	if cs.cached(filename, meta.ETag) {
+		return nil
+	}
+	cacheFills.Add(1)
+	r, err := cs.FileStorage.GetFileReader(ctx, filename, entity.ReadOptions{})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	e := &cacheEntry{name: filename, etag: meta.ETag, size: meta.Size}
+	if meta.Size <= cs.memMaxObjectSize {
+		e.data, err = readExactly(r, meta.Size)
+	} else {
+		e.path, err = cs.writeFile(r, filename, meta)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The file may have been overwritten while it was read, and the content
+	// then belongs to another ETag.
+	e.checked = time.Now()
+	now, err := cs.FileStorage.StatFile(ctx, filename)
+	if err == nil && now.ETag != meta.ETag {
+		err = errCacheStale
+	}
+	if err != nil {
+		if e.path != "" {
+			_ = os.Remove(e.path)
+		}
+		return err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	// A slow fill of an older version must not replace a newer one cached by
+	// a fill that checked its etag later.
+	for _, tier := range []*cacheLRU{cs.mem, cs.disk} {
+		if cur := tier.peek(filename); cur != nil && cur.etag != e.etag && cur.checked.After(e.checked) {
+			if e.path != "" {
+				_ = os.Remove(e.path)
+			}
+			return errCacheStale
+		}
+	}
+	if e.path == "" {
+		cs.disk.remove(filename)
+		cs.mem.add(e)
+	} else {
+		cs.mem.remove(filename)
+		cs.disk.add(e)
+	}
+	logger.FromContext(ctx).Debugw("file cached", "object", filename, "etag", meta.ETag, "size", meta.Size, "in_memory", e.path == "")
+	return nil
+}
+
+func (cs *cachedStorage) writeFile(r io.Reader, filename string, meta entity.FileMetadata) (string, error) {
+	tmp, err := os.CreateTemp(cs.dir, "*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	n, err := io.Copy(tmp, io.LimitReader(r, meta.Size+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if n != meta.Size {
+		return "", fmt.Errorf("read %d bytes of %s, want %d: %w", n, filename, meta.Size, errCacheStale)
+	}
+
+	sum := sha256.Sum256([]byte(filename + "\x00" + meta.ETag))
+	path := filepath.Join(cs.dir, hex.EncodeToString(sum[:])+".cache")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return path, nil
+}
+
+// readExactly reads r, which must hold exactly size bytes.
+func readExactly(r io.Reader, size int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("read %d bytes, want %d: %w", len(data), size, errCacheStale)
+	}
+	return data, nil
+}
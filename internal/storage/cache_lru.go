@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"container/list"
+)
+
+// cacheLRU holds cache entries by file name up to capacity bytes, evicting
+// the least recently used ones first. It is not safe for concurrent use.
+type cacheLRU struct {
+	capacity, size int64
+	ll             *list.List
+	items          map[string]*list.Element
+	onEvict        func(*cacheEntry)
+}
+
+func newCacheLRU(capacity int64, onEvict func(*cacheEntry)) *cacheLRU {
+	return &cacheLRU{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element), onEvict: onEvict}
+}
+
+// get returns the entry of name and marks it as recently used.
+func (c *cacheLRU) get(name string) *cacheEntry {
+	el, ok := c.items[name]
+	if !ok {
+		return nil
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*cacheEntry)
+}
+
+// peek returns the entry of name without marking it as used.
+func (c *cacheLRU) peek(name string) *cacheEntry {
+	if el, ok := c.items[name]; ok {
+		return el.Value.(*cacheEntry)
+	}
+	return nil
+}
+
+// add stores e in place of any entry of the same name and evicts entries
+// until the cache fits its capacity again.
+func (c *cacheLRU) add(e *cacheEntry) {
+	c.remove(e.name)
+	c.items[e.name] = c.ll.PushFront(e)
+	c.size += e.size
+	for c.size > c.capacity {
+		c.remove(c.ll.Back().Value.(*cacheEntry).name)
+	}
+}
+
+func (c *cacheLRU) remove(name string) {
+	el, ok := c.items[name]
+	if !ok {
+		return
+	}
+	e := c.ll.Remove(el).(*cacheEntry)
+	delete(c.items, name)
+	c.size -= e.size
+	c.onEvict(e)
+}
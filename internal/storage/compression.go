@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/minio-go/v7"
+	"io"
+	"strconv"
+)
+
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+
+	metaCompression = "Fs-Compression"
+	metaLogicalSize = "Fs-Logical-Size"
+)
+
+func IsSupportedCompression(alg string) bool {
+	return alg == CompressionGzip || alg == CompressionZstd
+}
+
+// copyCompressed writes r into w through the given compressor and returns the
+// number of uncompressed bytes consumed.
+func copyCompressed(w io.Writer, r io.Reader, alg string) (int64, error) {
+	var cw io.WriteCloser
+	switch alg {
+	case "":
+		return io.Copy(w, r)
+	case CompressionGzip:
+		cw = gzip.NewWriter(w)
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return 0, fmt.Errorf("failed to init zstd encoder: %w", err)
+		}
+		cw = enc
+	default:
+		return 0, fmt.Errorf("unsupported compression %q", alg)
+	}
+	n, err := io.Copy(cw, r)
+	if err != nil {
+		_ = cw.Close()
+		return n, err
+	}
+	if err := cw.Close(); err != nil {
+		return n, fmt.Errorf("failed to finish %s stream: %w", alg, err)
+	}
+	return n, nil
+}
+
+func newDecompressReader(r io.Reader, alg string) (io.ReadCloser, error) {
+	switch alg {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init zstd decoder: %w", err)
+		}
+		return dec.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression %q", alg)
+}
+
+func compressionOf(info minio.ObjectInfo) string {
+	return userMeta(info.UserMetadata, metaCompression)
+}
+
+// logicalSize is the size of the file as uploaded, before compression and
+// encryption.
+func logicalSize(info minio.ObjectInfo) int64 {
+	if n, err := strconv.ParseInt(userMeta(info.UserMetadata, metaLogicalSize), 10, 64); err == nil {
+		return n
+	}
+	size, err := plainSize(info)
+	if err != nil {
+		return info.Size
+	}
+	return size
+}
+
+// decompressRange decompresses the whole stored stream and returns the
+// requested range of the logical content.
+func decompressRange(stored io.ReadCloser, alg string, offset, length int64) (io.ReadCloser, error) {
+	dr, err := newDecompressReader(stored, alg)
+	if err != nil {
+		_ = stored.Close()
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, dr, offset); err != nil {
+			_ = dr.Close()
+			_ = stored.Close()
+			return nil, fmt.Errorf("failed to skip to offset %d: %w", offset, err)
+		}
+	}
+	var r io.Reader = dr
+	if length > 0 {
+		r = io.LimitReader(dr, length)
+	}
+	return readCloser{Reader: r, Closer: closers{dr, stored}}, nil
+}
+
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var first error
+	for _, c := range cs {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/minio/minio-go/v7"
+	"strings"
+)
+
+// checkConditions fails fast before the body is spooled; the same conditions
+// are sent with the PUT so that MinIO enforces them atomically.
+func (ms *MinioStorage) checkConditions(ctx context.Context, filename string, opts entity.UploadOptions) error {
+	if opts.IfNoneMatch == "" && opts.IfMatch == "" {
+		return nil
+	}
+	info, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{})
+	exists := err == nil
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to stat object for precondition: %w", err)
+	}
+	if opts.IfNoneMatch != "" && exists {
+		return conditionError(filename, opts)
+	}
+	if opts.IfMatch != "" && (!exists || info.ETag != normalizeETag(opts.IfMatch)) {
+		return conditionError(filename, opts)
+	}
+	return nil
+}
+
+func setConditions(putOpts *minio.PutObjectOptions, opts entity.UploadOptions) {
+	if opts.IfNoneMatch != "" {
+		putOpts.SetMatchETagExcept("*")
+	}
+	if opts.IfMatch != "" {
+		putOpts.SetMatchETag(normalizeETag(opts.IfMatch))
+	}
+}
+
+func conditionError(filename string, opts entity.UploadOptions) error {
+	if opts.IfNoneMatch != "" {
+		return fmt.Errorf("%w: %s", ErrAlreadyExists, filename)
+	}
+	return fmt.Errorf("%w: etag of %s does not match %s", ErrPreconditionFailed, filename, opts.IfMatch)
+}
+
+func checkReadCondition(filename, etag string, opts entity.ReadOptions) error {
+	if opts.IfMatch != "" && normalizeETag(etag) != normalizeETag(opts.IfMatch) {
+		return fmt.Errorf("%w: etag of %s does not match %s", ErrPreconditionFailed, filename, opts.IfMatch)
+	}
+	return nil
+}
+
+func normalizeETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/minio/minio-go/v7"
+)
+
+// maxCopySize is the largest object a single CopyObject call can copy; bigger
+// objects are copied part by part with ComposeObject.
+const maxCopySize = 5 * 1024 * 1024 * 1024
+
+func (ms *MinioStorage) CopyFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error) {
+	logger.FromContext(ctx).Infow("CopyFile called", "bucket", ms.bucket, "source", src, "destination", dst, "overwrite", overwrite)
+	_, up, err := ms.copyFile(ctx, src, dst, overwrite)
+	return up, err
+}
+
+// MoveFile copies src to dst and then removes src, unless src was replaced
+// in the meantime; the copy is then rolled back and src left alone. dst
+// takes its own blob reference, and the reference of src is released only
+// once src is gone, so that a failed move never leaves two files sharing one
+// reference.
+func (ms *MinioStorage) MoveFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("MoveFile called", "bucket", ms.bucket, "source", src, "destination", dst, "overwrite", overwrite)
+	info, up, err := ms.copyFile(ctx, src, dst, overwrite)
+	if err != nil {
+		return entity.UploadInfo{}, err
+	}
+	if err := ms.removeIfUnchanged(ctx, info); err != nil {
+		l.Errorw("failed to remove moved object, rolling back the copy", "object", src, "error", err)
+		copied := minio.ObjectInfo{Key: dst, ETag: up.ETag, VersionID: up.VersionID}
+		if rerr := ms.removeIfUnchanged(ctx, copied); rerr != nil {
+			l.Errorw("failed to roll back copy of moved object", "object", dst, "error", rerr)
+		} else {
+			ms.releaseMoved(ctx, dst, info)
+		}
+		return entity.UploadInfo{}, fmt.Errorf("failed to remove moved object: %w", err)
+	}
+	ms.releaseMoved(ctx, src, info)
+	l.Infow("object moved", "source", src, "destination", dst)
+	return up, nil
+}
+
+// releaseMoved releases the blob reference held by the removed object name,
+// a copy of info.
+func (ms *MinioStorage) releaseMoved(ctx context.Context, name string, info minio.ObjectInfo) {
+	if d := blobDigest(info); d != "" {
+		if err := ms.releaseBlob(ctx, d); err != nil {
+			logger.FromContext(ctx).Errorw("failed to release blob", "object", name, "digest", d, "error", err)
+		}
+	}
+}
+
+// removeIfUnchanged removes the object of info unless it was replaced or
+// removed since info was taken. MinIO has no conditional delete, so this
+// narrows the race with a concurrent write to the time between a stat and
+// the removal; on versioned buckets such a write stays a noncurrent version.
+func (ms *MinioStorage) removeIfUnchanged(ctx context.Context, info minio.ObjectInfo) error {
+	cur, err := ms.client.StatObject(ctx, ms.bucket, info.Key, minio.StatObjectOptions{})
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to stat object: %w", err)
+	}
+	if err != nil || cur.ETag != info.ETag || cur.VersionID != info.VersionID {
+		return fmt.Errorf("%w: %s was changed concurrently", ErrPreconditionFailed, info.Key)
+	}
+	if err := ms.client.RemoveObject(ctx, ms.bucket, info.Key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to remove object: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst server-side with its metadata and a blob
+// reference of its own, and returns the info of the copied src.
+func (ms *MinioStorage) copyFile(ctx context.Context, src, dst string, overwrite bool) (minio.ObjectInfo, entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	info, err := ms.client.StatObject(ctx, ms.bucket, src, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("%w: %s", ErrNotFound, src)
+		}
+		l.Errorw("failed to stat object", "object", src, "error", err)
+		return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+	target, err := ms.client.StatObject(ctx, ms.bucket, dst, minio.StatObjectOptions{})
+	switch {
+	case err == nil && !overwrite:
+		return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("%w: %s", ErrAlreadyExists, dst)
+	case err != nil && !isNotFound(err):
+		l.Errorw("failed to stat copy target", "object", dst, "error", err)
+		return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("failed to stat copy target: %w", err)
+	}
+
+	digest := blobDigest(info)
+	if digest != "" {
+		if err := ms.retainBlob(ctx, digest); err != nil {
+			l.Errorw("failed to retain blob", "object", src, "digest", digest, "error", err)
+			return minio.ObjectInfo{}, entity.UploadInfo{}, err
+		}
+	}
+
+	dstOpts := minio.CopyDestOptions{Bucket: ms.bucket, Object: dst, UserMetadata: copyMetadata(info.UserMetadata), ReplaceMetadata: true}
+	srcOpts := minio.CopySrcOptions{Bucket: ms.bucket, Object: src, VersionID: info.VersionID, MatchETag: info.ETag}
+	up, err := ms.copyObject(ctx, dstOpts, srcOpts, info.Size)
+	if err != nil {
+		if digest != "" {
+			if rerr := ms.releaseBlob(ctx, digest); rerr != nil {
+				l.Errorw("failed to release blob after failed copy", "digest", digest, "error", rerr)
+			}
+		}
+		if isPreconditionFailed(err) {
+			return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("%w: %s changed during copy", ErrPreconditionFailed, src)
+		}
+		l.Errorw("failed to copy object", "source", src, "destination", dst, "error", err)
+		return minio.ObjectInfo{}, entity.UploadInfo{}, fmt.Errorf("failed to copy object: %w", err)
+	}
+
+	if d := blobDigest(target); d != "" {
+		if err := ms.releaseBlob(ctx, d); err != nil {
+			l.Errorw("failed to release replaced blob", "object", dst, "digest", d, "error", err)
+		}
+	}
+	l.Infow("object copied", "source", src, "destination", dst, "version_id", up.VersionID)
+	return info, entity.UploadInfo{Name: dst, VersionID: up.VersionID, ETag: up.ETag, Size: logicalSize(info)}, nil
+}
+
+// copyObject copies an object of the given size server-side, part by part
+// when it is too big for a single CopyObject.
+func (ms *MinioStorage) copyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions, size int64) (minio.UploadInfo, error) {
+	if size > maxCopySize {
+		return ms.client.ComposeObject(ctx, dst, src)
+	}
+	return ms.client.CopyObject(ctx, dst, src)
+}
+
+// removeVersions removes key for good: on a versioned bucket a plain removal
+// only adds a delete marker, so every version is removed instead.
+func (ms *MinioStorage) removeVersions(ctx context.Context, key string) error {
+	if !ms.versioned {
+		return ms.client.RemoveObject(ctx, ms.bucket, key, minio.RemoveObjectOptions{})
+	}
+	for obj := range ms.client.ListObjects(ctx, ms.bucket, minio.ListObjectsOptions{Prefix: key, WithVersions: true}) {
+		if obj.Err != nil {
+			return fmt.Errorf("failed to list object versions: %w", obj.Err)
+		}
+		if obj.Key != key {
+			continue
+		}
+		if err := ms.client.RemoveObject(ctx, ms.bucket, key, minio.RemoveObjectOptions{VersionID: obj.VersionID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
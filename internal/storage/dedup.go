@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// In deduplication mode the content of a file is stored once as a blob under
+// .blobs/<sha256>, and the file itself becomes a small reference object whose
+// body is the digest. Blobs carry a reference count in the "refs" object tag
+// and are removed when the last reference goes away.
+
+const (
+	metaBlobDigest = "Fs-Blob-Digest"
+	metaStoredSize = "Fs-Stored-Size"
+
+	refCountTag = "refs"
+)
+
+func blobKey(digest string) string {
+	return blobPrefix + digest
+}
+
+func blobDigest(info minio.ObjectInfo) string {
+	return userMeta(info.UserMetadata, metaBlobDigest)
+}
+
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func (km *keyedMutex) lock(key string) func() {
+	km.mu.Lock()
+	if km.locks == nil {
+		km.locks = make(map[string]*keyedLock)
+	}
+	kl, ok := km.locks[key]
+	if !ok {
+		kl = &keyedLock{}
+		km.locks[key] = kl
+	}
+	kl.waiters++
+	km.mu.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+		km.mu.Lock()
+		kl.waiters--
+		if kl.waiters == 0 {
+			delete(km.locks, key)
+		}
+		km.mu.Unlock()
+	}
+}
+
+func (ms *MinioStorage) refCount(ctx context.Context, key string) (int, error) {
+	t, err := ms.client.GetObjectTagging(ctx, ms.bucket, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(t.ToMap()[refCountTag])
+	if err != nil {
+		return 0, fmt.Errorf("invalid reference count on %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func (ms *MinioStorage) setRefCount(ctx context.Context, key string, n int) error {
+	t, err := tags.NewTags(map[string]string{refCountTag: strconv.Itoa(n)}, true)
+	if err != nil {
+		return err
+	}
+	if err := ms.client.PutObjectTagging(ctx, ms.bucket, key, t, minio.PutObjectTaggingOptions{}); err != nil {
+		return fmt.Errorf("failed to set blob tags: %w", err)
+	}
+	return nil
+}
+
+// acquireBlob takes a reference on the blob for digest, uploading r as the
+// blob when it does not exist yet, and returns the blob's object info.
+func (ms *MinioStorage) acquireBlob(ctx context.Context, digest string, r io.Reader, size int64, meta map[string]string) (minio.ObjectInfo, error) {
+	l := logger.FromContext(ctx)
+	key := blobKey(digest)
+	unlock := ms.blobLocks.lock(digest)
+	defer unlock()
+
+	info, err := ms.client.StatObject(ctx, ms.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		if err := ms.incRefCount(ctx, digest); err != nil {
+			return minio.ObjectInfo{}, err
+		}
+		return info, nil
+	}
+	if !isNotFound(err) {
+		return minio.ObjectInfo{}, fmt.Errorf("failed to stat blob: %w", err)
+	}
+
+	reader, size, err := ms.seal(ctx, key, r, size, meta)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	putOpts := minio.PutObjectOptions{UserMetadata: meta, UserTags: map[string]string{refCountTag: "1"}}
+	if _, err := ms.client.PutObject(ctx, ms.bucket, key, reader, size, putOpts); err != nil {
+		return minio.ObjectInfo{}, fmt.Errorf("failed to upload blob: %w", err)
+	}
+	info, err = ms.client.StatObject(ctx, ms.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, fmt.Errorf("failed to stat blob: %w", err)
+	}
+	l.Infow("blob stored", "digest", digest, "size", size)
+	return info, nil
+}
+
+// retainBlob takes one more reference on an existing blob.
+func (ms *MinioStorage) retainBlob(ctx context.Context, digest string) error {
+	unlock := ms.blobLocks.lock(digest)
+	defer unlock()
+	return ms.incRefCount(ctx, digest)
+}
+
+// incRefCount must be called with the blob lock held.
+func (ms *MinioStorage) incRefCount(ctx context.Context, digest string) error {
+	key := blobKey(digest)
+	n, err := ms.refCount(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get blob reference count: %w", err)
+	}
+	if err := ms.setRefCount(ctx, key, n+1); err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Infow("blob reference taken", "digest", digest, "refs", n+1)
+	return nil
+}
+
+// releaseBlob drops one reference from the blob for digest and removes the
+// blob once nothing refers to it.
+func (ms *MinioStorage) releaseBlob(ctx context.Context, digest string) error {
+	l := logger.FromContext(ctx)
+	key := blobKey(digest)
+	unlock := ms.blobLocks.lock(digest)
+	defer unlock()
+
+	n, err := ms.refCount(ctx, key)
+	if err != nil {
+		if isNotFound(err) {
+			l.Warnw("released blob does not exist", "digest", digest)
+			return nil
+		}
+		return fmt.Errorf("failed to get blob reference count: %w", err)
+	}
+	if n > 1 {
+		l.Debugw("blob reference released", "digest", digest, "refs", n-1)
+		return ms.setRefCount(ctx, key, n-1)
+	}
+	if err := ms.client.RemoveObject(ctx, ms.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to remove blob: %w", err)
+	}
+	l.Infow("blob removed", "digest", digest)
+	return nil
+}
+
+// releaseRefs releases the blobs behind removed reference objects. Failures
+// only leak storage, so they are logged rather than returned.
+func (ms *MinioStorage) releaseRefs(ctx context.Context, digests map[string]string, removed []string) {
+	for _, name := range removed {
+		digest, ok := digests[name]
+		if !ok {
+			continue
+		}
+		if err := ms.releaseBlob(ctx, digest); err != nil {
+			logger.FromContext(ctx).Errorw("failed to release blob", "object", name, "digest", digest, "error", err)
+		}
+	}
+}
+
+// saveReference stores the spooled content as a blob and points filename at
+// it. blobMeta holds the content metadata (compression, logical size); meta
+// holds the per-file metadata (expiry, image info).
+//
+// The reference lock of filename is held from the stat of the replaced
+// reference to its release, so that concurrent overwrites do not both release
+// the same blob.
+func (ms *MinioStorage) saveReference(ctx context.Context, filename, digest string, r io.Reader, size int64, blobMeta, meta map[string]string, putOpts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	unlock := ms.refLocks.lock(filename)
+	defer unlock()
+
+	prev, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{})
+	if err != nil && !isNotFound(err) {
+		return minio.UploadInfo{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	blob, err := ms.acquireBlob(ctx, digest, r, size, blobMeta)
+	if err != nil {
+		l.Errorw("failed to store blob", "object", filename, "digest", digest, "error", err)
+		return minio.UploadInfo{}, err
+	}
+
+	meta[metaBlobDigest] = digest
+	meta[metaLogicalSize] = strconv.FormatInt(logicalSize(blob), 10)
+	stored, err := plainSize(blob)
+	if err != nil {
+		stored = blob.Size
+	}
+	meta[metaStoredSize] = strconv.FormatInt(stored, 10)
+	if c := compressionOf(blob); c != "" {
+		meta[metaCompression] = c
+	}
+	putOpts.UserMetadata = meta
+	info, err := ms.client.PutObject(ctx, ms.bucket, filename, strings.NewReader(digest), int64(len(digest)), putOpts)
+	if err != nil {
+		if rerr := ms.releaseBlob(ctx, digest); rerr != nil {
+			l.Errorw("failed to release blob after failed upload", "digest", digest, "error", rerr)
+		}
+		return minio.UploadInfo{}, err
+	}
+
+	if d := blobDigest(prev); d != "" {
+		if err := ms.releaseBlob(ctx, d); err != nil {
+			l.Errorw("failed to release replaced blob", "object", filename, "digest", d, "error", err)
+		}
+	}
+	return info, nil
+}
+
+// resolveBlob returns the info of the object holding the content of info,
+// which is the blob for reference objects and info itself otherwise.
+func (ms *MinioStorage) resolveBlob(ctx context.Context, info minio.ObjectInfo) (minio.ObjectInfo, error) {
+	digest := blobDigest(info)
+	if digest == "" {
+		return info, nil
+	}
+	blob, err := ms.client.StatObject(ctx, ms.bucket, blobKey(digest), minio.StatObjectOptions{})
+	if err != nil {
+		return minio.ObjectInfo{}, fmt.Errorf("failed to stat blob %s for %s: %w", digest, info.Key, err)
+	}
+	return blob, nil
+}
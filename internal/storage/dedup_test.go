@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDedupConcurrentOverwrites(t *testing.T) {
+	ctx := logger.WithContext(context.Background(), zap.NewNop())
+	ms, fake := newTestStorage(t, WithDeduplication())
+	save := func(name, data string) error {
+		_, err := ms.SaveFile(ctx, name, strings.NewReader(data), int64(len(data)), entity.UploadOptions{})
+		return err
+	}
+
+	// "a" and "b" share one blob.
+	for _, name := range []string{"a", "b"} {
+		if err := save(name, "shared"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, tags, _ := fake.Object(blobKey(sha256Hex("shared"))); tags[refCountTag] != "2" {
+		t.Fatalf("shared refs = %q, want 2", tags[refCountTag])
+	}
+
+	// Concurrent overwrites of "a" see the same replaced reference; each
+	// must release it only if its own write replaced it.
+	fake.PutDelay = 50 * time.Millisecond
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := save("a", fmt.Sprintf("new %d", i)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, tags, ok := fake.Object(blobKey(sha256Hex("shared"))); !ok || tags[refCountTag] != "1" {
+		t.Errorf("shared blob exists: %v, refs = %q, want 1", ok, tags[refCountTag])
+	}
+	ref, _, _ := fake.Object("a")
+	blobs := fake.Keys(blobPrefix)
+	if len(blobs) != 2 {
+		t.Errorf("blobs = %v, want the shared one and the one of a", blobs)
+	}
+	if _, tags, ok := fake.Object(blobKey(string(ref))); !ok || tags[refCountTag] != "1" {
+		t.Errorf("blob of a exists: %v, refs = %q, want 1", ok, tags[refCountTag])
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/encryption"
+	"github.com/minio/minio-go/v7"
+	"io"
+	"strconv"
+)
+
+const (
+	metaEncAlg   = "Fs-Enc-Alg"
+	metaEncKey   = "Fs-Enc-Key"
+	metaEncKeyID = "Fs-Enc-Key-Id"
+	metaEncChunk = "Fs-Enc-Chunk"
+)
+
+func userMeta(m map[string]string, key string) string {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return m["X-Amz-Meta-"+key]
+}
+
+func (ms *MinioStorage) encrypt(ctx context.Context, r io.Reader, size int64, meta map[string]string) (io.Reader, int64, error) {
+	plain, wrapped, keyID, err := ms.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	er, err := encryption.NewEncryptReader(r, plain, ms.encChunkSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to init encryption: %w", err)
+	}
+	meta[metaEncAlg] = encryption.Algorithm
+	meta[metaEncKey] = base64.StdEncoding.EncodeToString(wrapped)
+	meta[metaEncKeyID] = keyID
+	meta[metaEncChunk] = strconv.Itoa(ms.encChunkSize)
+	return er, encryption.EncryptedSize(size, ms.encChunkSize), nil
+}
+
+func isEncrypted(info minio.ObjectInfo) bool {
+	return userMeta(info.UserMetadata, metaEncAlg) != ""
+}
+
+func encChunkSize(info minio.ObjectInfo) (int, error) {
+	cs, err := strconv.Atoi(userMeta(info.UserMetadata, metaEncChunk))
+	if err != nil || cs <= 0 {
+		return 0, fmt.Errorf("invalid encryption chunk size on %s", info.Key)
+	}
+	return cs, nil
+}
+
+func plainSize(info minio.ObjectInfo) (int64, error) {
+	if !isEncrypted(info) {
+		return info.Size, nil
+	}
+	cs, err := encChunkSize(info)
+	if err != nil {
+		return 0, err
+	}
+	return encryption.PlainSize(info.Size, cs), nil
+}
+
+func (ms *MinioStorage) openDecrypted(ctx context.Context, info minio.ObjectInfo, offset, length int64) (io.ReadCloser, error) {
+	if alg := userMeta(info.UserMetadata, metaEncAlg); alg != encryption.Algorithm {
+		return nil, fmt.Errorf("unsupported encryption algorithm %q", alg)
+	}
+	if ms.keys == nil {
+		return nil, fmt.Errorf("object %s is encrypted but no key provider is configured", info.Key)
+	}
+	cs, err := encChunkSize(info)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(userMeta(info.UserMetadata, metaEncKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key on %s: %w", info.Key, err)
+	}
+	key, err := ms.keys.UnwrapDataKey(ctx, userMeta(info.UserMetadata, metaEncKeyID), wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	encOffset, encLength, firstChunk, skip := encryption.EncryptedRange(offset, length, info.Size, cs)
+	obj, err := ms.getObject(ctx, info, encOffset, encLength)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := encryption.NewDecryptReader(obj, key, cs, info.Size, firstChunk, skip)
+	if err != nil {
+		_ = obj.Close()
+		return nil, fmt.Errorf("failed to init decryption: %w", err)
+	}
+	var r io.Reader = dr
+	if length > 0 {
+		r = io.LimitReader(dr, length)
+	}
+	return readCloser{Reader: r, Closer: obj}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"errors"
+	"github.com/minio/minio-go/v7"
+	"net/http"
+)
+
+var (
+	ErrNotFound           = errors.New("file not found")
+	ErrInvalidRange       = errors.New("invalid range")
+	ErrAlreadyExists      = errors.New("file already exists")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+func isNotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.Code == "NoSuchKey" || resp.Code == "NoSuchVersion" || resp.StatusCode == http.StatusNotFound
+}
+
+func isPreconditionFailed(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.Code == "PreconditionFailed" || resp.StatusCode == http.StatusPreconditionFailed
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/minio/minio-go/v7"
+	"time"
+)
+
+const metaExpiresAt = "Fs-Expires-At"
+
+func expiresAt(meta map[string]string) time.Time {
+	v := userMeta(meta, metaExpiresAt)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func isExpired(meta map[string]string, now time.Time) bool {
+	exp := expiresAt(meta)
+	return !exp.IsZero() && !exp.After(now)
+}
+
+func (ms *MinioStorage) ReapExpired(ctx context.Context, now time.Time, batchSize int) ([]string, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("ReapExpired called", "bucket", ms.bucket, "now", now, "batch_size", batchSize)
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	var reaped []string
+	batch := make([]minio.ObjectInfo, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		names, err := ms.removeBatch(ctx, batch, now)
+		reaped = append(reaped, names...)
+		batch = batch[:0]
+		return err
+	}
+
+	opts := minio.ListObjectsOptions{Recursive: true, WithMetadata: true}
+	for obj := range ms.client.ListObjects(ctx, ms.bucket, opts) {
+		if obj.Err != nil {
+			l.Errorw("minio: list objects error", "error", obj.Err)
+			return reaped, fmt.Errorf("minio: list objects error: %w", obj.Err)
+		}
+		if IsReserved(obj.Key) || !isExpired(obj.UserMetadata, now) {
+			continue
+		}
+		batch = append(batch, minio.ObjectInfo{Key: obj.Key, ETag: obj.ETag, UserMetadata: obj.UserMetadata})
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return reaped, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return reaped, err
+	}
+	l.Infow("ReapExpired finished", "bucket", ms.bucket, "reaped", len(reaped))
+	return reaped, nil
+}
+
+// removeBatch removes the listed expired objects that are still the same
+// and still expired. An object overwritten since it was listed, e.g.
+// uploaded again without a TTL, must survive; MinIO has no conditional
+// delete, so each object is checked again right before the removal.
+func (ms *MinioStorage) removeBatch(ctx context.Context, batch []minio.ObjectInfo, now time.Time) ([]string, error) {
+	l := logger.FromContext(ctx)
+	objectsCh := make(chan minio.ObjectInfo, len(batch))
+	digests := make(map[string]string)
+	for _, obj := range batch {
+		cur, err := ms.client.StatObject(ctx, ms.bucket, obj.Key, minio.StatObjectOptions{})
+		if err != nil && !isNotFound(err) {
+			close(objectsCh)
+			return nil, fmt.Errorf("failed to stat expired object: %w", err)
+		}
+		if err != nil || cur.ETag != obj.ETag || !isExpired(cur.UserMetadata, now) {
+			l.Infow("expired object changed since listing, keeping it", "key", obj.Key)
+			continue
+		}
+		if d := blobDigest(cur); d != "" {
+			digests[obj.Key] = d
+		}
+		objectsCh <- minio.ObjectInfo{Key: obj.Key}
+	}
+	close(objectsCh)
+
+	var removed []string
+	var firstErr error
+	for res := range ms.client.RemoveObjectsWithResult(ctx, ms.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		if res.Err != nil {
+			l.Errorw("failed to remove object", "key", res.ObjectName, "error", res.Err)
+			if firstErr == nil {
+				firstErr = res.Err
+			}
+			continue
+		}
+		removed = append(removed, res.ObjectName)
+	}
+	ms.releaseRefs(ctx, digests, removed)
+	if firstErr != nil {
+		return removed, fmt.Errorf("failed to remove objects: %w", firstErr)
+	}
+	return removed, nil
+}
@@ -0,0 +1,297 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testBucket = "test"
+
+// fakeS3 is an in-memory S3 endpoint with just the calls the storage makes:
+// head, get, put, delete and object tagging, listing and multi-object
+// delete. It does not check signatures.
+type fakeS3 struct {
+	// PutDelay, if set, delays storing objects outside .blobs/, to widen
+	// races between concurrent writers of one name.
+	PutDelay time.Duration
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data     []byte
+	meta     http.Header
+	tags     map[string]string
+	modified time.Time
+}
+
+func (o fakeObject) etag() string {
+	sum := md5.Sum(o.data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newTestStorage serves a fakeS3 until the test ends and returns a storage
+// using it.
+func newTestStorage(t *testing.T, opts ...Option) (*MinioStorage, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{objects: make(map[string]fakeObject)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := minio.New(strings.TrimPrefix(srv.URL, "http://"), &minio.Options{
+		Region:       "us-east-1",
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMinioStorage(client, testBucket, opts...).(*MinioStorage), fake
+}
+
+// Object returns the data and tags stored under key.
+func (f *fakeS3) Object(key string) ([]byte, map[string]string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, ok := f.objects[key]
+	return o.data, o.tags, ok
+}
+
+// Keys returns the stored keys that start with prefix.
+func (f *fakeS3) Keys(prefix string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+type xmlTag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+type xmlTagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Tags    []xmlTag `xml:"TagSet>Tag"`
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !ok || key == "" {
+		f.serveBucket(w, r)
+		return
+	}
+	_, tagging := r.URL.Query()["tagging"]
+
+	f.mu.Lock()
+	o, exists := f.objects[key]
+	f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodHead || (r.Method == http.MethodGet && !tagging):
+		if !exists {
+			fakeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		hdr := w.Header()
+		for k, vs := range o.meta {
+			hdr[k] = vs
+		}
+		hdr.Set("ETag", `"`+o.etag()+`"`)
+		hdr.Set("Last-Modified", o.modified.Format(http.TimeFormat))
+		hdr.Set("Content-Length", strconv.Itoa(len(o.data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(o.data)
+		}
+
+	case r.Method == http.MethodGet:
+		if !exists {
+			fakeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		var t xmlTagging
+		for k, v := range o.tags {
+			t.Tags = append(t.Tags, xmlTag{Key: k, Value: v})
+		}
+		_ = xml.NewEncoder(w).Encode(t)
+
+	case r.Method == http.MethodPut && tagging:
+		var t xmlTagging
+		if err := xml.NewDecoder(r.Body).Decode(&t); err != nil {
+			fakeError(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		tags := make(map[string]string, len(t.Tags))
+		for _, tag := range t.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		o, ok := f.objects[key]
+		if !ok {
+			fakeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		o.tags = tags
+		f.objects[key] = o
+
+	case r.Method == http.MethodPut:
+		data, err := readBody(r)
+		if err != nil {
+			fakeError(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		o := fakeObject{data: data, meta: http.Header{}, tags: map[string]string{}, modified: time.Now()}
+		for k, vs := range r.Header {
+			if strings.HasPrefix(k, "X-Amz-Meta-") || k == "Content-Type" {
+				o.meta[k] = vs
+			}
+		}
+		if q, err := url.ParseQuery(r.Header.Get("X-Amz-Tagging")); err == nil {
+			for k := range q {
+				o.tags[k] = q.Get(k)
+			}
+		}
+		if f.PutDelay > 0 && !strings.HasPrefix(key, blobPrefix) {
+			time.Sleep(f.PutDelay)
+		}
+		f.mu.Lock()
+		f.objects[key] = o
+		f.mu.Unlock()
+		w.Header().Set("ETag", `"`+o.etag()+`"`)
+
+	case r.Method == http.MethodDelete:
+		f.mu.Lock()
+		delete(f.objects, key)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		fakeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+type xmlObject struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int       `xml:"Size"`
+}
+
+type xmlListResult struct {
+	XMLName     xml.Name    `xml:"ListBucketResult"`
+	Name        string      `xml:"Name"`
+	Prefix      string      `xml:"Prefix"`
+	KeyCount    int         `xml:"KeyCount"`
+	IsTruncated bool        `xml:"IsTruncated"`
+	Contents    []xmlObject `xml:"Contents"`
+}
+
+type xmlDelete struct {
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type xmlDeleteResult struct {
+	XMLName xml.Name `xml:"DeleteResult"`
+	Deleted []struct {
+		Key string `xml:"Key"`
+	} `xml:"Deleted"`
+}
+
+// serveBucket answers ListObjectsV2, in one page, and multi-object deletes.
+func (f *fakeS3) serveBucket(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && q.Get("list-type") == "2":
+		prefix := q.Get("prefix")
+		res := xmlListResult{Name: testBucket, Prefix: prefix}
+		f.mu.Lock()
+		for k, o := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				res.Contents = append(res.Contents, xmlObject{Key: k, LastModified: o.modified, ETag: `"` + o.etag() + `"`, Size: len(o.data)})
+			}
+		}
+		f.mu.Unlock()
+		sort.Slice(res.Contents, func(i, j int) bool { return res.Contents[i].Key < res.Contents[j].Key })
+		res.KeyCount = len(res.Contents)
+		_ = xml.NewEncoder(w).Encode(res)
+
+	case r.Method == http.MethodPost && q.Has("delete"):
+		var req xmlDelete
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			fakeError(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var res xmlDeleteResult
+		f.mu.Lock()
+		for _, o := range req.Objects {
+			delete(f.objects, o.Key)
+			res.Deleted = append(res.Deleted, struct {
+				Key string `xml:"Key"`
+			}{o.Key})
+		}
+		f.mu.Unlock()
+		_ = xml.NewEncoder(w).Encode(res)
+
+	default:
+		fakeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// readBody reads a request body, decoding the aws-chunked encoding the client
+// uses for streaming uploads.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Header.Get("X-Amz-Decoded-Content-Length") == "" {
+		return io.ReadAll(r.Body)
+	}
+	var data []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		n, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, n+2)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:n]...)
+	}
+}
+
+func fakeError(w http.ResponseWriter, code int, s3Code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<Error><Code>%s</Code><Message>%s</Message></Error>", s3Code, s3Code)
+	_, _ = w.Write(b.Bytes())
+}
@@ -4,10 +4,24 @@ import (
 	"context"
 	"github.com/PianyCoder/test_file_service/internal/entity"
 	"io"
+	"time"
 )
 
 type FileStorage interface {
-	SaveFile(ctx context.Context, filename string, r io.Reader, size int64) error
-	GetFileReader(ctx context.Context, filename string) (io.ReadCloser, error)
+	SaveFile(ctx context.Context, filename string, r io.Reader, size int64, opts entity.UploadOptions) (entity.UploadInfo, error)
+	GetFileReader(ctx context.Context, filename string, opts entity.ReadOptions) (io.ReadCloser, error)
+	StatFile(ctx context.Context, filename string) (entity.FileMetadata, error)
 	ListAllFilesMetadata(ctx context.Context) ([]entity.FileMetadata, error)
+	ListFileVersions(ctx context.Context, filename string) ([]entity.FileVersion, error)
+	RestoreFileVersion(ctx context.Context, filename, versionID string) (entity.UploadInfo, error)
+	CopyFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error)
+	MoveFile(ctx context.Context, src, dst string, overwrite bool) (entity.UploadInfo, error)
+	SetAttributes(ctx context.Context, filename string, attrs map[string]string) (entity.FileMetadata, error)
+	MoveToTrash(ctx context.Context, filename, ifMatch string) (entity.TrashItem, error)
+	ListTrash(ctx context.Context) ([]entity.TrashItem, error)
+	RestoreFromTrash(ctx context.Context, id string, overwrite bool) (entity.UploadInfo, error)
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error)
+	ReapExpired(ctx context.Context, now time.Time, batchSize int) ([]string, error)
+	SweepRenditions(ctx context.Context, batchSize int) (int, error)
+	ListenChanges(ctx context.Context, emit func(entity.FileEvent)) error
 }
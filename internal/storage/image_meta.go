@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"strconv"
+	"time"
+)
+
+const (
+	metaImageFormat      = "Fs-Image-Format"
+	metaImageWidth       = "Fs-Image-Width"
+	metaImageHeight      = "Fs-Image-Height"
+	metaImageOrientation = "Fs-Image-Orientation"
+	metaImageCapturedAt  = "Fs-Image-Captured-At"
+)
+
+func setImageInfo(meta map[string]string, info *entity.ImageInfo) {
+	if info == nil {
+		return
+	}
+	meta[metaImageFormat] = info.Format
+	meta[metaImageWidth] = strconv.Itoa(info.Width)
+	meta[metaImageHeight] = strconv.Itoa(info.Height)
+	if info.Orientation > 0 {
+		meta[metaImageOrientation] = strconv.Itoa(info.Orientation)
+	}
+	if !info.CapturedAt.IsZero() {
+		meta[metaImageCapturedAt] = info.CapturedAt.Format(time.RFC3339)
+	}
+}
+
+func imageInfo(meta map[string]string) *entity.ImageInfo {
+	format := userMeta(meta, metaImageFormat)
+	if format == "" {
+		return nil
+	}
+	info := &entity.ImageInfo{Format: format}
+	info.Width, _ = strconv.Atoi(userMeta(meta, metaImageWidth))
+	info.Height, _ = strconv.Atoi(userMeta(meta, metaImageHeight))
+	info.Orientation, _ = strconv.Atoi(userMeta(meta, metaImageOrientation))
+	if v := userMeta(meta, metaImageCapturedAt); v != "" {
+		info.CapturedAt, _ = time.Parse(time.RFC3339, v)
+	}
+	return info
+}
@@ -1,65 +1,106 @@
 package storage
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/encryption"
 	"github.com/PianyCoder/test_file_service/internal/entity"
 	"github.com/minio/minio-go/v7"
 	"io"
 	"os"
+	"strconv"
+	"time"
 )
 
 type MinioStorage struct {
-	client *minio.Client
-	bucket string
+	client       *minio.Client
+	bucket       string
+	keys         encryption.KeyProvider
+	encChunkSize int
+	dedup        bool
+	versioned    bool
+	blobLocks    keyedMutex
+	refLocks     keyedMutex // per filename, around replacing a reference object
+
+	partSize        int
+	partParallelism int // 0 spools uploads of unknown size to a temp file
+
+	rangeWindow      int
+	rangeParallelism int // 0 reads objects with a single GetObject
 }
 
-func NewMinioStorage(client *minio.Client, bucket string) FileStorage {
-	return &MinioStorage{client: client, bucket: bucket}
+func NewMinioStorage(client *minio.Client, bucket string, opts ...Option) FileStorage {
+	ms := &MinioStorage{client: client, bucket: bucket}
+	for _, opt := range opts {
+		opt(ms)
+	}
+	return ms
 }
 
-func (ms *MinioStorage) SaveFile(ctx context.Context, filename string, r io.Reader, size int64) error {
+func (ms *MinioStorage) SaveFile(ctx context.Context, filename string, r io.Reader, size int64, opts entity.UploadOptions) (entity.UploadInfo, error) {
 	l := logger.FromContext(ctx)
-	l.Infow("storage.SaveFile called", "filename", filename, "size", size)
+	l.Infow("storage.SaveFile called", "filename", filename, "size", size, "if_none_match", opts.IfNoneMatch, "if_match", opts.IfMatch, "compression", opts.Compression)
+
+	if err := ms.checkConditions(ctx, filename, opts); err != nil {
+		l.Warnw("upload precondition not met", "filename", filename, "error", err)
+		return entity.UploadInfo{}, err
+	}
+
+	dedup := ms.dedup && !IsReserved(filename)
+	if ms.useMultipart(size, opts, dedup) {
+		return ms.saveMultipart(ctx, filename, r, opts)
+	}
 
 	var reader io.Reader = r
 	var cleanup func()
 	var objectSize int64 = size
+	logicalObjectSize := size
+	var digest string
 
-	if size < 0 {
-		l.Debugw("size unknown, buffering to temp file", "filename", filename)
+	if size < 0 || opts.Compression != "" || dedup {
+		l.Debugw("buffering to temp file", "filename", filename, "size", size, "compression", opts.Compression)
 		f, err := os.CreateTemp("", "upload-*")
 		if err != nil {
 			l.Errorw("failed to create temp file", "error", err)
-			return fmt.Errorf("failed to create temp file: %w", err)
+			return entity.UploadInfo{}, fmt.Errorf("failed to create temp file: %w", err)
 		}
 		tempName := f.Name()
 		cleanup = func() { _ = os.Remove(tempName); _ = f.Close() }
 
-		n, err := io.Copy(f, r)
+		h := sha256.New()
+		n, err := copyCompressed(f, io.TeeReader(r, h), opts.Compression)
 		if err != nil {
 			_ = f.Close()
 			_ = os.Remove(tempName)
 			l.Errorw("failed to write temp file", "temp", tempName, "error", err)
-			return fmt.Errorf("failed to write temp file: %w", err)
+			return entity.UploadInfo{}, fmt.Errorf("failed to write temp file: %w", err)
 		}
 		l.Infow("temp file written", "temp", tempName, "bytes", n)
+		logicalObjectSize = n
+		digest = hex.EncodeToString(h.Sum(nil))
 
 		stat, err := f.Stat()
 		if err != nil {
 			_ = f.Close()
 			_ = os.Remove(tempName)
 			l.Errorw("failed to stat temp file", "temp", tempName, "error", err)
-			return fmt.Errorf("failed to stat temp file: %w", err)
+			return entity.UploadInfo{}, fmt.Errorf("failed to stat temp file: %w", err)
 		}
 		objectSize = stat.Size()
+		if opts.Compression != "" {
+			l.Infow("file compressed", "filename", filename, "compression", opts.Compression, "logical_size", n, "stored_size", objectSize)
+		}
 
 		if _, err := f.Seek(0, io.SeekStart); err != nil {
 			_ = f.Close()
 			_ = os.Remove(tempName)
 			l.Errorw("failed to seek temp file", "temp", tempName, "error", err)
-			return fmt.Errorf("failed to seek temp file: %w", err)
+			return entity.UploadInfo{}, fmt.Errorf("failed to seek temp file: %w", err)
 		}
 		reader = f
 	} else {
@@ -67,25 +108,169 @@ func (ms *MinioStorage) SaveFile(ctx context.Context, filename string, r io.Read
 		cleanup = func() {}
 	}
 
-	l.Infow("putting object to minio", "bucket", ms.bucket, "object", filename, "size", objectSize)
-	_, err := ms.client.PutObject(ctx, ms.bucket, filename, reader, objectSize, minio.PutObjectOptions{})
-	if cleanup != nil {
+	contentMeta := map[string]string{}
+	if opts.Compression != "" {
+		contentMeta[metaCompression] = opts.Compression
+		contentMeta[metaLogicalSize] = strconv.FormatInt(logicalObjectSize, 10)
+	}
+	meta := fileMeta(opts)
+	putOpts := minio.PutObjectOptions{}
+	setConditions(&putOpts, opts)
+
+	var info minio.UploadInfo
+	var err error
+	if dedup {
+		l.Infow("storing deduplicated object", "bucket", ms.bucket, "object", filename, "digest", digest)
+		info, err = ms.saveReference(ctx, filename, digest, reader, objectSize, contentMeta, meta, putOpts)
+		cleanup()
+	} else {
+		for k, v := range contentMeta {
+			meta[k] = v
+		}
+		reader, objectSize, err = ms.seal(ctx, filename, reader, objectSize, meta)
+		if err != nil {
+			cleanup()
+			return entity.UploadInfo{}, err
+		}
+		l.Infow("putting object to minio", "bucket", ms.bucket, "object", filename, "size", objectSize)
+		putOpts.UserMetadata = meta
+		info, err = ms.client.PutObject(ctx, ms.bucket, filename, reader, objectSize, putOpts)
 		cleanup()
 	}
+	if err != nil && isPreconditionFailed(err) {
+		l.Warnw("upload precondition failed in minio", "object", filename, "error", err)
+		return entity.UploadInfo{}, conditionError(filename, opts)
+	}
 	if err != nil {
 		l.Errorw("failed to upload file to minio", "bucket", ms.bucket, "object", filename, "error", err)
-		return fmt.Errorf("failed to upload file to minio: %w", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to upload file to minio: %w", err)
+	}
+	l.Infow("file uploaded to minio", "bucket", ms.bucket, "object", filename, "size", logicalObjectSize, "version_id", info.VersionID)
+	return entity.UploadInfo{Name: filename, VersionID: info.VersionID, ETag: info.ETag, Size: logicalObjectSize}, nil
+}
+
+// seal encrypts the object body when client-side encryption is configured,
+// recording the key material in meta.
+func (ms *MinioStorage) seal(ctx context.Context, key string, r io.Reader, size int64, meta map[string]string) (io.Reader, int64, error) {
+	if ms.keys == nil {
+		return r, size, nil
+	}
+	l := logger.FromContext(ctx)
+	er, encSize, err := ms.encrypt(ctx, r, size, meta)
+	if err != nil {
+		l.Errorw("failed to encrypt object", "object", key, "error", err)
+		return nil, 0, fmt.Errorf("failed to encrypt object: %w", err)
+	}
+	l.Debugw("encrypting object", "object", key, "plain_size", size, "stored_size", encSize)
+	return er, encSize, nil
+}
+
+// fileMeta returns the per-file metadata of an upload: expiry and image info.
+func fileMeta(opts entity.UploadOptions) map[string]string {
+	meta := map[string]string{}
+	if !opts.ExpiresAt.IsZero() {
+		meta[metaExpiresAt] = opts.ExpiresAt.UTC().Format(time.RFC3339)
 	}
-	l.Infow("file uploaded to minio", "bucket", ms.bucket, "object", filename, "size", objectSize)
-	return nil
+	setImageInfo(meta, opts.ImageInfo)
+	return meta
 }
 
-func (ms *MinioStorage) GetFileReader(ctx context.Context, filename string) (io.ReadCloser, error) {
+func (ms *MinioStorage) GetFileReader(ctx context.Context, filename string, opts entity.ReadOptions) (io.ReadCloser, error) {
 	l := logger.FromContext(ctx)
-	l.Infow("GetFileReader called", "bucket", ms.bucket, "object", filename)
-	obj, err := ms.client.GetObject(ctx, ms.bucket, filename, minio.GetObjectOptions{})
+	l.Infow("GetFileReader called", "bucket", ms.bucket, "object", filename, "version_id", opts.VersionID, "offset", opts.Offset, "length", opts.Length, "raw", opts.Raw)
+	info, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{VersionID: opts.VersionID})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, filename)
+		}
+		l.Errorw("failed to stat object in minio", "bucket", ms.bucket, "object", filename, "error", err)
+		return nil, fmt.Errorf("failed to stat object in minio: %w", err)
+	}
+	if isExpired(info.UserMetadata, time.Now()) {
+		l.Infow("object is expired", "bucket", ms.bucket, "object", filename)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, filename)
+	}
+	// The read below is conditional on the etag of this stat, so checking
+	// it here pins the whole read to opts.IfMatch.
+	if err := checkReadCondition(filename, info.ETag, opts); err != nil {
+		l.Warnw("read precondition failed", "object", filename, "etag", info.ETag, "if_match", opts.IfMatch)
+		return nil, err
+	}
+	if info, err = ms.resolveBlob(ctx, info); err != nil {
+		l.Errorw("failed to resolve blob", "bucket", ms.bucket, "object", filename, "error", err)
+		return nil, err
+	}
+
+	size, err := plainSize(info)
+	if err != nil {
+		return nil, err
+	}
+	compression := compressionOf(info)
+	decompress := compression != "" && !opts.Raw
+	if decompress {
+		size = logicalSize(info)
+	}
+	if opts.Offset < 0 || opts.Offset > size {
+		return nil, fmt.Errorf("%w: offset %d, size %d", ErrInvalidRange, opts.Offset, size)
+	}
+	length := opts.Length
+	if length > size-opts.Offset {
+		length = size - opts.Offset
+	}
+	if opts.Offset == size && size > 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	if !decompress {
+		return ms.openStored(ctx, info, opts.Offset, length)
+	}
+	stored, err := ms.openStored(ctx, info, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decompressRange(stored, compression, opts.Offset, length)
+	if err != nil {
+		l.Errorw("failed to decompress object", "bucket", ms.bucket, "object", filename, "compression", compression, "error", err)
+		return nil, fmt.Errorf("failed to decompress object: %w", err)
+	}
+	return r, nil
+}
+
+// openStored returns the stored (possibly compressed) bytes of the object,
+// decrypting them when needed.
+func (ms *MinioStorage) openStored(ctx context.Context, info minio.ObjectInfo, offset, length int64) (io.ReadCloser, error) {
+	if !isEncrypted(info) {
+		return ms.getObject(ctx, info, offset, length)
+	}
+	r, err := ms.openDecrypted(ctx, info, offset, length)
+	if err != nil {
+		logger.FromContext(ctx).Errorw("failed to open encrypted object", "bucket", ms.bucket, "object", info.Key, "error", err)
+		return nil, fmt.Errorf("failed to open encrypted object: %w", err)
+	}
+	return r, nil
+}
+
+func (ms *MinioStorage) getObject(ctx context.Context, info minio.ObjectInfo, offset, length int64) (io.ReadCloser, error) {
+	if n := cmp.Or(length, info.Size-offset); ms.useRanged(n) {
+		return ms.getRanged(ctx, info, offset, n), nil
+	}
+	opts := minio.GetObjectOptions{VersionID: info.VersionID}
+	if err := opts.SetMatchETag(info.ETag); err != nil {
+		return nil, fmt.Errorf("failed to set etag condition: %w", err)
+	}
+	switch {
+	case length > 0:
+		if err := opts.SetRange(offset, offset+length-1); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRange, err)
+		}
+	case offset > 0:
+		if err := opts.SetRange(offset, 0); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRange, err)
+		}
+	}
+	obj, err := ms.client.GetObject(ctx, ms.bucket, info.Key, opts)
 	if err != nil {
-		l.Errorw("failed to get object from minio", "bucket", ms.bucket, "object", filename, "error", err)
+		logger.FromContext(ctx).Errorw("failed to get object from minio", "bucket", ms.bucket, "object", info.Key, "error", err)
 		return nil, fmt.Errorf("failed to get object from minio: %w", err)
 	}
 	return obj, nil
@@ -96,9 +281,11 @@ func (ms *MinioStorage) ListAllFilesMetadata(ctx context.Context) ([]entity.File
 	l.Infow("ListAllFilesMetadata called", "bucket", ms.bucket)
 	var metadata []entity.FileMetadata
 	opts := minio.ListObjectsOptions{
-		Recursive: true,
+		Recursive:    true,
+		WithMetadata: true,
 	}
 
+	now := time.Now()
 	objectCh := ms.client.ListObjects(ctx, ms.bucket, opts)
 	count := 0
 	for obj := range objectCh {
@@ -106,13 +293,113 @@ func (ms *MinioStorage) ListAllFilesMetadata(ctx context.Context) ([]entity.File
 			l.Errorw("minio: list objects error", "error", obj.Err)
 			return nil, fmt.Errorf("minio: list objects error: %w", obj.Err)
 		}
-		metadata = append(metadata, entity.FileMetadata{
-			Name:      obj.Key,
-			CreatedAt: obj.LastModified,
-			UpdatedAt: obj.LastModified,
-		})
+		if IsReserved(obj.Key) || isExpired(obj.UserMetadata, now) {
+			continue
+		}
+		metadata = append(metadata, fileMetadata(obj))
 		count++
 	}
 	l.Infow("ListAllFilesMetadata finished", "bucket", ms.bucket, "files", count)
 	return metadata, nil
 }
+
+func (ms *MinioStorage) ListFileVersions(ctx context.Context, filename string) ([]entity.FileVersion, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("ListFileVersions called", "bucket", ms.bucket, "object", filename)
+	var versions []entity.FileVersion
+	opts := minio.ListObjectsOptions{
+		Prefix:       filename,
+		WithVersions: true,
+		WithMetadata: true,
+	}
+
+	for obj := range ms.client.ListObjects(ctx, ms.bucket, opts) {
+		if obj.Err != nil {
+			l.Errorw("minio: list object versions error", "error", obj.Err)
+			return nil, fmt.Errorf("minio: list object versions error: %w", obj.Err)
+		}
+		if obj.Key != filename {
+			continue
+		}
+		size := logicalSize(obj)
+		versions = append(versions, entity.FileVersion{
+			Name:           obj.Key,
+			VersionID:      obj.VersionID,
+			Size:           size,
+			ETag:           obj.ETag,
+			IsLatest:       obj.IsLatest,
+			IsDeleteMarker: obj.IsDeleteMarker,
+			UpdatedAt:      obj.LastModified,
+		})
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, filename)
+	}
+	l.Infow("ListFileVersions finished", "bucket", ms.bucket, "object", filename, "versions", len(versions))
+	return versions, nil
+}
+
+func (ms *MinioStorage) RestoreFileVersion(ctx context.Context, filename, versionID string) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("RestoreFileVersion called", "bucket", ms.bucket, "object", filename, "version_id", versionID)
+	src, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{VersionID: versionID})
+	if err != nil {
+		if isNotFound(err) {
+			return entity.UploadInfo{}, fmt.Errorf("%w: %s@%s", ErrNotFound, filename, versionID)
+		}
+		l.Errorw("failed to stat object version", "object", filename, "version_id", versionID, "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to stat object version: %w", err)
+	}
+
+	info, err := ms.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: ms.bucket, Object: filename},
+		minio.CopySrcOptions{Bucket: ms.bucket, Object: filename, VersionID: versionID},
+	)
+	if err != nil {
+		l.Errorw("failed to restore object version", "object", filename, "version_id", versionID, "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to restore object version: %w", err)
+	}
+	size := logicalSize(src)
+	l.Infow("object version restored", "object", filename, "from_version", versionID, "new_version", info.VersionID)
+	return entity.UploadInfo{Name: filename, VersionID: info.VersionID, ETag: info.ETag, Size: size}, nil
+}
+
+func (ms *MinioStorage) StatFile(ctx context.Context, filename string) (entity.FileMetadata, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("StatFile called", "bucket", ms.bucket, "object", filename)
+	info, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return entity.FileMetadata{}, fmt.Errorf("%w: %s", ErrNotFound, filename)
+		}
+		l.Errorw("failed to stat object in minio", "bucket", ms.bucket, "object", filename, "error", err)
+		return entity.FileMetadata{}, fmt.Errorf("failed to stat object in minio: %w", err)
+	}
+	if isExpired(info.UserMetadata, time.Now()) {
+		return entity.FileMetadata{}, fmt.Errorf("%w: %s", ErrNotFound, filename)
+	}
+	return fileMetadata(info), nil
+}
+
+func fileMetadata(info minio.ObjectInfo) entity.FileMetadata {
+	stored, err := plainSize(info)
+	if err != nil {
+		stored = info.Size
+	}
+	if n, err := strconv.ParseInt(userMeta(info.UserMetadata, metaStoredSize), 10, 64); err == nil {
+		stored = n
+	}
+	return entity.FileMetadata{
+		Name:        info.Key,
+		Size:        logicalSize(info),
+		StoredSize:  stored,
+		Compression: compressionOf(info),
+		Digest:      blobDigest(info),
+		ETag:        info.ETag,
+		CreatedAt:   info.LastModified,
+		UpdatedAt:   info.LastModified,
+		ExpiresAt:   expiresAt(info.UserMetadata),
+		ImageInfo:   imageInfo(info.UserMetadata),
+		Attributes:  attributes(info.UserMetadata),
+	}
+}
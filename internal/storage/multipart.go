@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/bufpool"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/sync/errgroup"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultPartSize = 8 << 20
+	// MinPartSize and maxParts are the S3 limits on multipart uploads.
+	MinPartSize = 5 << 20
+	maxParts    = 10000
+
+	// abortTimeout bounds the abort of a failed multipart upload, which runs
+	// even when the upload failed because its context was canceled.
+	abortTimeout = 30 * time.Second
+)
+
+// useMultipart tells whether an upload goes to MinIO in parallel parts.
+// Compressed and deduplicated uploads need their whole content before they
+// are stored, and uploads of known size stream to PutObject directly.
+func (ms *MinioStorage) useMultipart(size int64, opts entity.UploadOptions, dedup bool) bool {
+	return ms.partParallelism > 0 && size < 0 && opts.Compression == "" && !dedup
+}
+
+// saveMultipart stores r without spooling it to disk: it is read a part at
+// a time, and up to partParallelism parts are uploaded while the next one is
+// read. Content shorter than one part is stored with a single PutObject.
+func (ms *MinioStorage) saveMultipart(ctx context.Context, filename string, r io.Reader, opts entity.UploadOptions) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	meta := fileMeta(opts)
+	counted := &countingReader{r: r}
+	reader, _, err := ms.seal(ctx, filename, counted, -1, meta)
+	if err != nil {
+		return entity.UploadInfo{}, err
+	}
+
+	info, err := ms.putParts(ctx, filename, reader, meta, opts)
+	if err != nil && isPreconditionFailed(err) {
+		l.Warnw("upload precondition failed in minio", "object", filename, "error", err)
+		return entity.UploadInfo{}, conditionError(filename, opts)
+	}
+	if err != nil {
+		l.Errorw("failed to upload file to minio", "bucket", ms.bucket, "object", filename, "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to upload file to minio: %w", err)
+	}
+	l.Infow("file uploaded to minio", "bucket", ms.bucket, "object", filename, "size", counted.n, "version_id", info.VersionID)
+	return entity.UploadInfo{Name: filename, VersionID: info.VersionID, ETag: info.ETag, Size: counted.n}, nil
+}
+
+func (ms *MinioStorage) putParts(ctx context.Context, filename string, r io.Reader, meta map[string]string, opts entity.UploadOptions) (minio.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	first := bufpool.Get(ms.partSize)
+	n, err := io.ReadFull(r, first)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		defer bufpool.Put(first)
+		putOpts := minio.PutObjectOptions{UserMetadata: meta}
+		setConditions(&putOpts, opts)
+		l.Infow("putting object to minio", "bucket", ms.bucket, "object", filename, "size", n)
+		return ms.client.PutObject(ctx, ms.bucket, filename, bytes.NewReader(first[:n]), int64(n), putOpts)
+	}
+	if err != nil {
+		bufpool.Put(first)
+		return minio.UploadInfo{}, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	core := minio.Core{Client: ms.client}
+	uploadID, err := core.NewMultipartUpload(ctx, ms.bucket, filename, minio.PutObjectOptions{UserMetadata: meta})
+	if err != nil {
+		bufpool.Put(first)
+		return minio.UploadInfo{}, fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+	l.Infow("multipart upload started", "bucket", ms.bucket, "object", filename, "upload_id", uploadID, "part_size", ms.partSize, "parallelism", ms.partParallelism)
+
+	parts, err := ms.uploadParts(ctx, core, filename, uploadID, r, first)
+	if err == nil {
+		// Conditions are checked when the parts are put together.
+		var completeOpts minio.PutObjectOptions
+		setConditions(&completeOpts, opts)
+		var info minio.UploadInfo
+		info, err = core.CompleteMultipartUpload(ctx, ms.bucket, filename, uploadID, parts, completeOpts)
+		if err == nil {
+			l.Infow("multipart upload completed", "object", filename, "upload_id", uploadID, "parts", len(parts))
+			return info, nil
+		}
+	}
+
+	actx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+	if aerr := core.AbortMultipartUpload(actx, ms.bucket, filename, uploadID); aerr != nil {
+		l.Errorw("failed to abort multipart upload", "object", filename, "upload_id", uploadID, "error", aerr)
+	} else {
+		l.Infow("multipart upload aborted", "object", filename, "upload_id", uploadID, "cause", err)
+	}
+	return minio.UploadInfo{}, err
+}
+
+// uploadParts uploads first and the rest of r as numbered parts. Reading
+// stops at the first failed part, and a failed read fails the upload even
+// when all parts so far succeeded.
+func (ms *MinioStorage) uploadParts(ctx context.Context, core minio.Core, filename, uploadID string, r io.Reader, first []byte) ([]minio.CompletePart, error) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(ms.partParallelism)
+	var mu sync.Mutex
+	var parts []minio.CompletePart
+
+	var readErr error
+	part, last := first, false
+	for number := 1; ; number++ {
+		if number > maxParts {
+			bufpool.Put(part)
+			readErr = fmt.Errorf("upload exceeds %d parts of %d bytes", maxParts, ms.partSize)
+			break
+		}
+		// Go blocks while partParallelism parts are in flight, which holds
+		// back the read of the next part.
+		data := part
+		g.Go(func() error {
+			defer bufpool.Put(data)
+			p, err := core.PutObjectPart(gctx, ms.bucket, filename, uploadID, number, bytes.NewReader(data), int64(len(data)), minio.PutObjectPartOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", number, err)
+			}
+			mu.Lock()
+			parts = append(parts, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+			mu.Unlock()
+			return nil
+		})
+		if last || gctx.Err() != nil {
+			break
+		}
+
+		buf := bufpool.Get(ms.partSize)
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			bufpool.Put(buf)
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			bufpool.Put(buf)
+			readErr = fmt.Errorf("failed to read upload: %w", err)
+			break
+		}
+		part, last = buf[:n], err == io.ErrUnexpectedEOF
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, errors.Join(err, readErr)
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
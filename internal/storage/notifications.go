@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var notificationEvents = []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+
+// ListenChanges streams bucket notifications into emit until ctx is done or
+// the notification stream fails. MinIO cannot tell a new object from an
+// overwritten one, so every write is reported as created.
+func (ms *MinioStorage) ListenChanges(ctx context.Context, emit func(entity.FileEvent)) error {
+	l := logger.FromContext(ctx)
+	l.Infow("listening for bucket notifications", "bucket", ms.bucket)
+	for info := range ms.client.ListenBucketNotification(ctx, ms.bucket, "", "", notificationEvents) {
+		if info.Err != nil {
+			return fmt.Errorf("bucket notification error: %w", info.Err)
+		}
+		for _, rec := range info.Records {
+			name, err := url.QueryUnescape(rec.S3.Object.Key)
+			if err != nil {
+				l.Warnw("skipping notification with malformed key", "key", rec.S3.Object.Key)
+				continue
+			}
+			if IsReserved(name) {
+				continue
+			}
+			e := entity.FileEvent{
+				Type:      entity.EventCreated,
+				Name:      name,
+				Size:      rec.S3.Object.Size,
+				ETag:      rec.S3.Object.ETag,
+				VersionID: rec.S3.Object.VersionID,
+			}
+			if strings.HasPrefix(rec.EventName, "s3:ObjectRemoved:") {
+				e.Type = entity.EventDeleted
+			} else if m, err := ms.StatFile(ctx, name); err == nil {
+				// The notification carries the stored size, which differs
+				// for compressed and deduplicated files.
+				e.Size = m.Size
+			}
+			if t, err := time.Parse(time.RFC3339Nano, rec.EventTime); err == nil {
+				e.Time = t
+			}
+			emit(e)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.New("bucket notification stream closed")
+}
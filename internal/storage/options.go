@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"github.com/PianyCoder/test_file_service/internal/encryption"
+)
+
+type Option func(*MinioStorage)
+
+func WithEncryption(keys encryption.KeyProvider, chunkSize int) Option {
+	return func(ms *MinioStorage) {
+		if chunkSize <= 0 {
+			chunkSize = encryption.DefaultChunkSize
+		}
+		ms.keys = keys
+		ms.encChunkSize = chunkSize
+	}
+}
+
+// WithVersioning tells the storage that the bucket keeps object versions,
+// so that purging the trash removes every version of a trashed file.
+func WithVersioning() Option {
+	return func(ms *MinioStorage) {
+		ms.versioned = true
+	}
+}
+
+// WithDeduplication stores identical content once and keeps files as
+// references to it. Reference counts are guarded by an in-process lock, so a
+// bucket must not be shared by several deduplicating instances, and it must
+// not be versioned: old versions would keep references that are never counted.
+func WithDeduplication() Option {
+	return func(ms *MinioStorage) {
+		ms.dedup = true
+	}
+}
+
+// WithParallelMultipart stores uploads of unknown size as multipart uploads
+// of partSize bytes with up to parallelism parts in flight, instead of
+// spooling them to a temp file first. Each upload then holds up to
+// parallelism+1 parts in memory. Compressed and deduplicated uploads still
+// spool.
+func WithParallelMultipart(partSize, parallelism int) Option {
+	return func(ms *MinioStorage) {
+		if partSize <= 0 {
+			partSize = DefaultPartSize
+		}
+		ms.partSize = max(partSize, MinPartSize)
+		ms.partParallelism = max(parallelism, 1)
+	}
+}
+
+// WithParallelRangedReads reads objects larger than windowSize as
+// consecutive ranges of windowSize bytes, with up to parallelism ranges
+// fetched at once, which hides the latency of each request on distant object
+// stores. Each read then holds up to parallelism windows in memory.
+func WithParallelRangedReads(windowSize, parallelism int) Option {
+	return func(ms *MinioStorage) {
+		if windowSize <= 0 {
+			windowSize = DefaultRangeWindow
+		}
+		ms.rangeWindow = max(windowSize, MinRangeWindow)
+		ms.rangeParallelism = max(parallelism, 1)
+	}
+}
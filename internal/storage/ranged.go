@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/bufpool"
+	"github.com/minio/minio-go/v7"
+	"io"
+	"sync"
+)
+
+const (
+	DefaultRangeWindow = 8 << 20
+	MinRangeWindow     = 1 << 20
+)
+
+// useRanged tells whether length bytes of an object are fetched as parallel
+// ranges. A read that fits in one window gains nothing from it.
+func (ms *MinioStorage) useRanged(length int64) bool {
+	return ms.rangeParallelism > 0 && length > int64(ms.rangeWindow)
+}
+
+// rangedReader reads [offset, end) of an object by fetching consecutive
+// windows with up to parallelism GetObject requests in flight, and returns
+// them in order. Every request is conditional on the ETag seen when the read
+// started, so that a replaced object fails the read instead of mixing
+// contents.
+type rangedReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	ms     *MinioStorage
+	info   minio.ObjectInfo
+
+	next, end int64         // next range to fetch and end of the read
+	pending   []*rangeFetch // fetches in flight, in order
+	cur       []byte        // unread part of pending[0]
+	wg        sync.WaitGroup
+	err       error
+}
+
+type rangeFetch struct {
+	done chan struct{}
+	buf  []byte
+	err  error
+}
+
+func (ms *MinioStorage) getRanged(ctx context.Context, info minio.ObjectInfo, offset, length int64) io.ReadCloser {
+	logger.FromContext(ctx).Debugw("fetching object in parallel ranges", "object", info.Key, "offset", offset, "length", length, "window", ms.rangeWindow, "parallelism", ms.rangeParallelism)
+	ctx, cancel := context.WithCancel(ctx)
+	rr := &rangedReader{ctx: ctx, cancel: cancel, ms: ms, info: info, next: offset, end: offset + length}
+	for len(rr.pending) < ms.rangeParallelism && rr.next < rr.end {
+		rr.fetchNext()
+	}
+	return rr
+}
+
+func (rr *rangedReader) fetchNext() {
+	start := rr.next
+	n := min(int64(rr.ms.rangeWindow), rr.end-start)
+	rr.next += n
+	f := &rangeFetch{done: make(chan struct{})}
+	rr.pending = append(rr.pending, f)
+	rr.wg.Add(1)
+	go func() {
+		defer rr.wg.Done()
+		defer close(f.done)
+		f.buf, f.err = rr.fetch(start, int(n))
+	}()
+}
+
+func (rr *rangedReader) fetch(offset int64, n int) ([]byte, error) {
+	opts := minio.GetObjectOptions{VersionID: rr.info.VersionID}
+	if err := opts.SetMatchETag(rr.info.ETag); err != nil {
+		return nil, fmt.Errorf("failed to set etag condition: %w", err)
+	}
+	if err := opts.SetRange(offset, offset+int64(n)-1); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRange, err)
+	}
+	obj, err := rr.ms.client.GetObject(rr.ctx, rr.ms.bucket, rr.info.Key, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object range from minio: %w", err)
+	}
+	defer obj.Close()
+
+	buf := bufpool.Get(n)
+	if _, err := io.ReadFull(obj, buf); err != nil {
+		bufpool.Put(buf)
+		return nil, fmt.Errorf("failed to read object range %d-%d from minio: %w", offset, offset+int64(n)-1, err)
+	}
+	return buf, nil
+}
+
+func (rr *rangedReader) Read(p []byte) (int, error) {
+	for len(rr.cur) == 0 {
+		if rr.err != nil {
+			return 0, rr.err
+		}
+		if len(rr.pending) == 0 {
+			return 0, io.EOF
+		}
+		f := rr.pending[0]
+		select {
+		case <-f.done:
+		case <-rr.ctx.Done():
+			rr.err = rr.ctx.Err()
+			return 0, rr.err
+		}
+		if f.err != nil {
+			logger.FromContext(rr.ctx).Errorw("failed to fetch object range", "bucket", rr.ms.bucket, "object", rr.info.Key, "error", f.err)
+			rr.err = f.err
+			rr.cancel()
+			return 0, rr.err
+		}
+		rr.cur = f.buf
+	}
+	n := copy(p, rr.cur)
+	rr.cur = rr.cur[n:]
+	if len(rr.cur) == 0 {
+		rr.advance()
+	}
+	return n, nil
+}
+
+// advance drops the consumed window and starts the fetch that replaces it.
+func (rr *rangedReader) advance() {
+	bufpool.Put(rr.pending[0].buf)
+	rr.pending = rr.pending[1:]
+	if rr.next < rr.end {
+		rr.fetchNext()
+	}
+}
+
+// Close stops the fetches in flight and returns their buffers.
+func (rr *rangedReader) Close() error {
+	rr.cancel()
+	rr.wg.Wait()
+	for _, f := range rr.pending {
+		if f.buf != nil {
+			bufpool.Put(f.buf)
+		}
+	}
+	rr.pending, rr.cur = nil, nil
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/minio/minio-go/v7"
+	"strings"
+	"time"
+)
+
+// renditionSource splits a rendition key, .renditions/<name>/<etag>/<variant>,
+// into the name and ETag of the source it was rendered from.
+func renditionSource(key string) (name, etag string, ok bool) {
+	rest := strings.TrimPrefix(key, RenditionPrefix)
+	i := strings.LastIndexByte(rest, '/')
+	if i < 0 {
+		return "", "", false
+	}
+	j := strings.LastIndexByte(rest[:i], '/')
+	if j <= 0 {
+		return "", "", false
+	}
+	return rest[:j], rest[j+1 : i], true
+}
+
+// SweepRenditions removes cached renditions whose source is gone or has been
+// replaced since they were rendered, and returns how many it removed.
+// Renditions are keyed by the source ETag, so they are never served stale,
+// but nothing else removes them once the source changes.
+func (ms *MinioStorage) SweepRenditions(ctx context.Context, batchSize int) (int, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("SweepRenditions called", "bucket", ms.bucket, "batch_size", batchSize)
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	current := make(map[string]string) // source name to its ETag, "" if gone
+	sourceETag := func(name string) (string, error) {
+		if etag, ok := current[name]; ok {
+			return etag, nil
+		}
+		info, err := ms.client.StatObject(ctx, ms.bucket, name, minio.StatObjectOptions{})
+		switch {
+		case isNotFound(err):
+			current[name] = ""
+		case err != nil:
+			return "", fmt.Errorf("failed to stat rendition source: %w", err)
+		case isExpired(info.UserMetadata, time.Now()):
+			current[name] = ""
+		default:
+			current[name] = normalizeETag(info.ETag)
+		}
+		return current[name], nil
+	}
+
+	removed := 0
+	batch := make([]string, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		objectsCh := make(chan minio.ObjectInfo, len(batch))
+		for _, key := range batch {
+			objectsCh <- minio.ObjectInfo{Key: key}
+		}
+		close(objectsCh)
+		batch = batch[:0]
+		var firstErr error
+		for res := range ms.client.RemoveObjectsWithResult(ctx, ms.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+			if res.Err != nil {
+				l.Errorw("failed to remove rendition", "key", res.ObjectName, "error", res.Err)
+				if firstErr == nil {
+					firstErr = res.Err
+				}
+				continue
+			}
+			removed++
+		}
+		if firstErr != nil {
+			return fmt.Errorf("failed to remove renditions: %w", firstErr)
+		}
+		return nil
+	}
+
+	opts := minio.ListObjectsOptions{Prefix: RenditionPrefix, Recursive: true}
+	for obj := range ms.client.ListObjects(ctx, ms.bucket, opts) {
+		if obj.Err != nil {
+			l.Errorw("minio: list objects error", "error", obj.Err)
+			return removed, fmt.Errorf("minio: list objects error: %w", obj.Err)
+		}
+		name, etag, ok := renditionSource(obj.Key)
+		if !ok {
+			continue
+		}
+		cur, err := sourceETag(name)
+		if err != nil {
+			return removed, err
+		}
+		if cur == etag {
+			continue
+		}
+		batch = append(batch, obj.Key)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return removed, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return removed, err
+	}
+	l.Infow("SweepRenditions finished", "bucket", ms.bucket, "removed", removed)
+	return removed, nil
+}
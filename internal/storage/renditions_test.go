@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSweepRenditions(t *testing.T) {
+	ctx := logger.WithContext(context.Background(), zap.NewNop())
+	ms, fake := newTestStorage(t)
+	save := func(name, data string, opts entity.UploadOptions) entity.UploadInfo {
+		t.Helper()
+		info, err := ms.SaveFile(ctx, name, strings.NewReader(data), int64(len(data)), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	rendition := func(name, etag string) string {
+		key := RenditionPrefix + name + "/" + normalizeETag(etag) + "/10x10-fit.jpeg"
+		save(key, "pixels", entity.UploadOptions{})
+		return key
+	}
+
+	current := save("photos/a.jpg", "a", entity.UploadOptions{})
+	keep := rendition("photos/a.jpg", current.ETag)
+	replaced := rendition("photos/a.jpg", "0123")
+	deleted := rendition("gone.jpg", "4567")
+	expiring := save("b.jpg", "b", entity.UploadOptions{ExpiresAt: time.Now().Add(time.Hour)})
+	expired := rendition("b.jpg", expiring.ETag)
+	// b.jpg expires while its rendition is still stored.
+	fake.mu.Lock()
+	o := fake.objects["b.jpg"]
+	for k := range o.meta {
+		if strings.EqualFold(k, "X-Amz-Meta-"+metaExpiresAt) {
+			o.meta[k] = []string{time.Now().Add(-time.Minute).Format(time.RFC3339)}
+		}
+	}
+	fake.mu.Unlock()
+
+	n, err := ms.SweepRenditions(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("removed %d renditions, want 3", n)
+	}
+	if got := fake.Keys(RenditionPrefix); !slices.Equal(got, []string{keep}) {
+		t.Errorf("renditions left = %v, want only %s (removed %s, %s, %s)", got, keep, replaced, deleted, expired)
+	}
+}
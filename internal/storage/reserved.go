@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"strings"
+)
+
+const (
+	trashPrefix     = ".trash/"
+	blobPrefix      = ".blobs/"
+	RenditionPrefix = ".renditions/"
+)
+
+var reservedPrefixes = []string{trashPrefix, blobPrefix, RenditionPrefix}
+
+// IsReserved reports whether key belongs to the service's internal namespaces.
+func IsReserved(key string) bool {
+	for _, p := range reservedPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
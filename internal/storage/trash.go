@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"github.com/minio/minio-go/v7"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metaTrashOriginalName = "Fs-Trash-Original-Name"
+	metaTrashDeletedAt    = "Fs-Trash-Deleted-At"
+)
+
+// Trashed objects live under .trash/<deleted-at-unix-nano>/<original-name>;
+// the part after the prefix is the trash item id.
+
+func trashKey(filename string, deletedAt time.Time) string {
+	return trashPrefix + strconv.FormatInt(deletedAt.UnixNano(), 10) + "/" + filename
+}
+
+func parseTrashID(id string) (string, time.Time, error) {
+	ts, name, ok := strings.Cut(id, "/")
+	if !ok || name == "" {
+		return "", time.Time{}, fmt.Errorf("%w: malformed trash id %q", ErrNotFound, id)
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: malformed trash id %q", ErrNotFound, id)
+	}
+	return name, time.Unix(0, nanos), nil
+}
+
+func copyMetadata(src map[string]string) map[string]string {
+	meta := make(map[string]string, len(src)+2)
+	for k, v := range src {
+		meta[strings.TrimPrefix(k, "X-Amz-Meta-")] = v
+	}
+	return meta
+}
+
+func (ms *MinioStorage) MoveToTrash(ctx context.Context, filename, ifMatch string) (entity.TrashItem, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("MoveToTrash called", "bucket", ms.bucket, "object", filename, "if_match", ifMatch)
+	src, err := ms.client.StatObject(ctx, ms.bucket, filename, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return entity.TrashItem{}, fmt.Errorf("%w: %s", ErrNotFound, filename)
+		}
+		l.Errorw("failed to stat object", "object", filename, "error", err)
+		return entity.TrashItem{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+	// The copy and the removal below are pinned to the ETag of src.
+	if ifMatch != "" && normalizeETag(src.ETag) != normalizeETag(ifMatch) {
+		return entity.TrashItem{}, fmt.Errorf("%w: %s has etag %s, want %s", ErrPreconditionFailed, filename, src.ETag, ifMatch)
+	}
+
+	deletedAt := time.Now().UTC()
+	key := trashKey(filename, deletedAt)
+	meta := copyMetadata(src.UserMetadata)
+	meta[metaTrashOriginalName] = filename
+	meta[metaTrashDeletedAt] = deletedAt.Format(time.RFC3339Nano)
+
+	// The trash object takes a blob reference of its own, and the one of
+	// filename is released once filename is gone, as in MoveFile.
+	digest := blobDigest(src)
+	if digest != "" {
+		if err := ms.retainBlob(ctx, digest); err != nil {
+			l.Errorw("failed to retain blob", "object", filename, "digest", digest, "error", err)
+			return entity.TrashItem{}, err
+		}
+	}
+	_, err = ms.copyObject(ctx,
+		minio.CopyDestOptions{Bucket: ms.bucket, Object: key, UserMetadata: meta, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: ms.bucket, Object: filename, VersionID: src.VersionID, MatchETag: src.ETag},
+		src.Size,
+	)
+	if err != nil {
+		l.Errorw("failed to copy object to trash", "object", filename, "trash_key", key, "error", err)
+		ms.releaseMoved(ctx, key, src)
+		if isPreconditionFailed(err) {
+			return entity.TrashItem{}, fmt.Errorf("%w: %s changed during delete", ErrPreconditionFailed, filename)
+		}
+		return entity.TrashItem{}, fmt.Errorf("failed to copy object to trash: %w", err)
+	}
+	if err := ms.removeIfUnchanged(ctx, src); err != nil {
+		l.Errorw("failed to remove trashed object, rolling back", "object", filename, "error", err)
+		if rerr := ms.removeVersions(ctx, key); rerr != nil {
+			l.Errorw("failed to roll back trash object", "trash_key", key, "error", rerr)
+		} else {
+			ms.releaseMoved(ctx, key, src)
+		}
+		return entity.TrashItem{}, fmt.Errorf("failed to remove object: %w", err)
+	}
+	ms.releaseMoved(ctx, filename, src)
+
+	size := logicalSize(src)
+	l.Infow("object moved to trash", "object", filename, "trash_key", key)
+	return entity.TrashItem{
+		ID:           strings.TrimPrefix(key, trashPrefix),
+		OriginalName: filename,
+		Size:         size,
+		DeletedAt:    deletedAt,
+	}, nil
+}
+
+func (ms *MinioStorage) ListTrash(ctx context.Context) ([]entity.TrashItem, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("ListTrash called", "bucket", ms.bucket)
+	var items []entity.TrashItem
+	opts := minio.ListObjectsOptions{
+		Prefix:       trashPrefix,
+		Recursive:    true,
+		WithMetadata: true,
+	}
+
+	for obj := range ms.client.ListObjects(ctx, ms.bucket, opts) {
+		if obj.Err != nil {
+			l.Errorw("minio: list trash error", "error", obj.Err)
+			return nil, fmt.Errorf("minio: list trash error: %w", obj.Err)
+		}
+		id := strings.TrimPrefix(obj.Key, trashPrefix)
+		name, deletedAt, err := parseTrashID(id)
+		if err != nil {
+			l.Warnw("skipping malformed trash object", "key", obj.Key)
+			continue
+		}
+		size := logicalSize(obj)
+		items = append(items, entity.TrashItem{ID: id, OriginalName: name, Size: size, DeletedAt: deletedAt})
+	}
+	l.Infow("ListTrash finished", "bucket", ms.bucket, "items", len(items))
+	return items, nil
+}
+
+func (ms *MinioStorage) RestoreFromTrash(ctx context.Context, id string, overwrite bool) (entity.UploadInfo, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("RestoreFromTrash called", "bucket", ms.bucket, "trash_id", id, "overwrite", overwrite)
+	name, _, err := parseTrashID(id)
+	if err != nil {
+		return entity.UploadInfo{}, err
+	}
+	key := trashPrefix + id
+	src, err := ms.client.StatObject(ctx, ms.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return entity.UploadInfo{}, fmt.Errorf("%w: trash item %s", ErrNotFound, id)
+		}
+		l.Errorw("failed to stat trash object", "trash_key", key, "error", err)
+		return entity.UploadInfo{}, fmt.Errorf("failed to stat trash object: %w", err)
+	}
+	target, err := ms.client.StatObject(ctx, ms.bucket, name, minio.StatObjectOptions{})
+	switch {
+	case err == nil && !overwrite:
+		return entity.UploadInfo{}, fmt.Errorf("%w: %s", ErrAlreadyExists, name)
+	case err != nil && !isNotFound(err):
+		return entity.UploadInfo{}, fmt.Errorf("failed to stat restore target: %w", err)
+	}
+
+	meta := copyMetadata(src.UserMetadata)
+	delete(meta, metaTrashOriginalName)
+	delete(meta, metaTrashDeletedAt)
+	digest := blobDigest(src)
+	if digest != "" {
+		if err := ms.retainBlob(ctx, digest); err != nil {
+			l.Errorw("failed to retain blob", "trash_key", key, "digest", digest, "error", err)
+			return entity.UploadInfo{}, err
+		}
+	}
+	info, err := ms.copyObject(ctx,
+		minio.CopyDestOptions{Bucket: ms.bucket, Object: name, UserMetadata: meta, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: ms.bucket, Object: key, MatchETag: src.ETag},
+		src.Size,
+	)
+	if err != nil {
+		l.Errorw("failed to restore object from trash", "trash_key", key, "object", name, "error", err)
+		ms.releaseMoved(ctx, name, src)
+		return entity.UploadInfo{}, fmt.Errorf("failed to restore object from trash: %w", err)
+	}
+	if err := ms.removeVersions(ctx, key); err != nil {
+		// The trash item stays, holding a blob reference of its own.
+		l.Warnw("failed to remove restored trash object", "trash_key", key, "error", err)
+	} else {
+		ms.releaseMoved(ctx, key, src)
+	}
+	if d := blobDigest(target); d != "" {
+		if err := ms.releaseBlob(ctx, d); err != nil {
+			l.Errorw("failed to release replaced blob", "object", name, "digest", d, "error", err)
+		}
+	}
+
+	size := logicalSize(src)
+	l.Infow("object restored from trash", "trash_key", key, "object", name, "version_id", info.VersionID)
+	return entity.UploadInfo{Name: name, VersionID: info.VersionID, ETag: info.ETag, Size: size}, nil
+}
+
+func (ms *MinioStorage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	l := logger.FromContext(ctx)
+	l.Infow("PurgeTrash called", "bucket", ms.bucket, "deleted_before", deletedBefore)
+
+	objectsCh := make(chan minio.ObjectInfo)
+	listErr := make(chan error, 1)
+	var mu sync.Mutex
+	digests := make(map[string]string)
+	go func() {
+		defer close(objectsCh)
+		// On a versioned bucket every version is removed, delete markers
+		// included; without a version id only a delete marker is added.
+		opts := minio.ListObjectsOptions{Prefix: trashPrefix, Recursive: true, WithMetadata: !ms.versioned, WithVersions: ms.versioned}
+		for obj := range ms.client.ListObjects(ctx, ms.bucket, opts) {
+			if obj.Err != nil {
+				listErr <- obj.Err
+				return
+			}
+			_, deletedAt, err := parseTrashID(strings.TrimPrefix(obj.Key, trashPrefix))
+			if err != nil || !deletedAt.Before(deletedBefore) {
+				continue
+			}
+			if d := blobDigest(obj); d != "" {
+				mu.Lock()
+				digests[obj.Key] = d
+				mu.Unlock()
+			}
+			select {
+			case objectsCh <- obj:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var purged []string
+	items := make(map[string]bool)
+	var firstErr error
+	for res := range ms.client.RemoveObjectsWithResult(ctx, ms.bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		if res.Err != nil {
+			l.Errorw("failed to purge trash object", "key", res.ObjectName, "error", res.Err)
+			if firstErr == nil {
+				firstErr = res.Err
+			}
+			continue
+		}
+		purged = append(purged, res.ObjectName)
+		items[res.ObjectName] = true
+	}
+	mu.Lock()
+	ms.releaseRefs(ctx, digests, purged)
+	mu.Unlock()
+	select {
+	case err := <-listErr:
+		l.Errorw("minio: list trash error", "error", err)
+		return len(items), fmt.Errorf("minio: list trash error: %w", err)
+	default:
+	}
+	if firstErr != nil {
+		return len(items), fmt.Errorf("failed to purge trash: %w", firstErr)
+	}
+	l.Infow("PurgeTrash finished", "bucket", ms.bucket, "purged", len(items))
+	return len(items), nil
+}
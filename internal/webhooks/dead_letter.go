@@ -0,0 +1,51 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeadLetter is a delivery that was given up on.
+type DeadLetter struct {
+	Subscription string    `json:"subscription"`
+	URL          string    `json:"url"`
+	Payload      Payload   `json:"payload"`
+	Attempts     int       `json:"attempts"`
+	Error        string    `json:"error"`
+	Time         time.Time `json:"time"`
+}
+
+// DeadLetterLog appends dead letters to a file as JSON lines so that they can
+// be inspected and replayed by hand.
+type DeadLetterLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func OpenDeadLetterLog(path string) (*DeadLetterLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter log: %w", err)
+	}
+	return &DeadLetterLog{file: f}, nil
+}
+
+func (d *DeadLetterLog) Write(dl DeadLetter) error {
+	line, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+func (d *DeadLetterLog) Close() error {
+	return d.file.Close()
+}
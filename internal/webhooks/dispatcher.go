@@ -0,0 +1,259 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/infrastructure/logger"
+	"github.com/PianyCoder/test_file_service/infrastructure/metrics"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+)
+
+// SignatureTolerance is how far the timestamp of a signed delivery may be
+// from the clock of the receiver before Verify rejects it as a replay.
+const SignatureTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+var (
+	deliveries       = metrics.Counter("webhook_deliveries_total")
+	deliveryFailures = metrics.Counter("webhook_delivery_failures_total")
+	deadLetters      = metrics.Counter("webhook_dead_letters_total")
+)
+
+// Payload is the JSON body posted to subscribers.
+type Payload struct {
+	ID        string           `json:"id"`
+	Type      entity.EventType `json:"type"`
+	Name      string           `json:"name"`
+	Size      int64            `json:"size"`
+	ETag      string           `json:"etag,omitempty"`
+	VersionID string           `json:"version_id,omitempty"`
+	Time      time.Time        `json:"time"`
+}
+
+type Options struct {
+	QueueSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+}
+
+// Dispatcher delivers file events to webhook subscriptions. Every
+// subscription has its own queue and worker, so a slow or failing endpoint
+// neither blocks the others nor reorders its own events.
+type Dispatcher struct {
+	workers    []*worker
+	client     *http.Client
+	opts       Options
+	deadLetter *DeadLetterLog
+}
+
+type worker struct {
+	sub   Subscription
+	queue chan Payload
+}
+
+func NewDispatcher(subs []Subscription, deadLetter *DeadLetterLog, opts Options) *Dispatcher {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1000
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = opts.InitialBackoff
+	}
+	d := &Dispatcher{
+		client:     &http.Client{Timeout: opts.Timeout},
+		opts:       opts,
+		deadLetter: deadLetter,
+	}
+	for _, s := range subs {
+		d.workers = append(d.workers, &worker{sub: s, queue: make(chan Payload, opts.QueueSize)})
+	}
+	return d
+}
+
+// Enqueue hands e to every matching subscription without blocking. When a
+// subscription's queue is full the event goes straight to the dead letter log.
+func (d *Dispatcher) Enqueue(ctx context.Context, e entity.FileEvent) {
+	p := Payload{
+		ID:        e.Cursor,
+		Type:      e.Type,
+		Name:      e.Name,
+		Size:      e.Size,
+		ETag:      e.ETag,
+		VersionID: e.VersionID,
+		Time:      e.Time,
+	}
+	for _, w := range d.workers {
+		if !w.sub.Matches(e) {
+			continue
+		}
+		select {
+		case w.queue <- p:
+		default:
+			d.bury(ctx, w.sub, p, 0, fmt.Errorf("delivery queue full"))
+		}
+	}
+}
+
+// Run delivers queued events until ctx is done. Events still queued at that
+// point are dropped.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, w := range d.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx, w)
+		}()
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) work(ctx context.Context, w *worker) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-w.queue:
+			d.deliver(ctx, w.sub, p)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscription, p Payload) {
+	l := logger.FromContext(ctx)
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.bury(ctx, sub, p, 0, fmt.Errorf("failed to encode payload: %w", err))
+		return
+	}
+
+	backoff := d.opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := d.post(ctx, sub, p, body)
+		if err == nil {
+			deliveries.Add(1)
+			l.Debugw("webhook delivered", "subscription", sub.ID, "event_id", p.ID, "attempt", attempt)
+			return
+		}
+		deliveryFailures.Add(1)
+		if ctx.Err() != nil {
+			return
+		}
+		if attempt >= d.opts.MaxAttempts {
+			d.bury(ctx, sub, p, attempt, err)
+			return
+		}
+		// Jitter keeps retries of events queued together from staying in step.
+		wait := time.Duration(rand.Int64N(int64(backoff))) + backoff/2
+		l.Warnw("webhook delivery failed, retrying", "error", err, "subscription", sub.ID, "event_id", p.ID, "attempt", attempt, "retry_in", wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, d.opts.MaxBackoff)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub Subscription, p Payload, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(p.Type))
+	req.Header.Set(HeaderDelivery, p.ID)
+	if sub.Secret != "" {
+		// Every attempt is signed anew, so that retries stay within the
+		// tolerance of the receiver.
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (d *Dispatcher) bury(ctx context.Context, sub Subscription, p Payload, attempts int, cause error) {
+	l := logger.FromContext(ctx)
+	deadLetters.Add(1)
+	l.Errorw("webhook delivery given up", "error", cause, "subscription", sub.ID, "event_id", p.ID, "attempts", attempts)
+	if d.deadLetter == nil {
+		return
+	}
+	err := d.deadLetter.Write(DeadLetter{
+		Subscription: sub.ID,
+		URL:          sub.URL,
+		Payload:      p,
+		Attempts:     attempts,
+		Error:        cause.Error(),
+		Time:         time.Now().UTC(),
+	})
+	if err != nil {
+		l.Errorw("failed to record dead letter", "error", err, "subscription", sub.ID, "event_id", p.ID)
+	}
+}
+
+// Sign returns the X-Webhook-Signature value for a delivery: "sha256="
+// followed by the hex HMAC-SHA256, keyed with secret, of the
+// X-Webhook-Timestamp value, a dot and the raw request body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a delivery received
+// at now. Deliveries signed more than SignatureTolerance away from now are
+// rejected, so that a captured request cannot be replayed later.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > SignatureTolerance || d < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/PianyCoder/test_file_service/internal/entity"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Subscription describes one webhook endpoint. An empty Events list matches
+// every event type.
+type Subscription struct {
+	ID     string             `json:"id"`
+	URL    string             `json:"url"`
+	Events []entity.EventType `json:"events"`
+	Prefix string             `json:"prefix"`
+	Secret string             `json:"secret"`
+}
+
+func (s Subscription) Matches(e entity.FileEvent) bool {
+	if !strings.HasPrefix(e.Name, s.Prefix) {
+		return false
+	}
+	return len(s.Events) == 0 || slices.Contains(s.Events, e.Type)
+}
+
+// LoadSubscriptions reads a JSON array of subscriptions from path.
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file: %w", err)
+	}
+	var subs []Subscription
+	if err := json.Unmarshal(raw, &subs); err != nil {
+		return nil, fmt.Errorf("invalid webhooks file %s: %w", path, err)
+	}
+	seen := make(map[string]bool, len(subs))
+	for i, s := range subs {
+		if s.ID == "" {
+			return nil, fmt.Errorf("invalid webhooks file %s: subscription %d has no id", path, i)
+		}
+		if seen[s.ID] {
+			return nil, fmt.Errorf("invalid webhooks file %s: duplicate subscription id %q", path, s.ID)
+		}
+		seen[s.ID] = true
+		if err := validate(s); err != nil {
+			return nil, fmt.Errorf("invalid webhooks file %s: subscription %q: %w", path, s.ID, err)
+		}
+	}
+	return subs, nil
+}
+
+func validate(s Subscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) url")
+	}
+	for _, t := range s.Events {
+		switch t {
+		case entity.EventCreated, entity.EventUpdated, entity.EventDeleted:
+		default:
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package client is a Go SDK for the file service. It wraps the generated
+// gRPC client with streaming uploads and downloads, listing iterators,
+// retries of transient failures and a random-access remote file handle.
+//
+//	conn, err := grpc.NewClient("localhost:8000", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	...
+//	c := client.New(conn)
+//	_, err = c.Upload(ctx, "photos/cat.jpg", f)
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"iter"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultChunkSize = 256 << 10 // used when the upload size is unknown
+	MinChunkSize     = 32 << 10
+	MaxChunkSize     = 2 << 20 // well below the 4 MiB default gRPC message limit
+)
+
+var (
+	// ErrNotFound is returned, wrapped, when a file does not exist.
+	ErrNotFound = errors.New("file not found")
+	// ErrChanged is returned, wrapped, when a file was replaced while it was
+	// being read by several calls.
+	ErrChanged = errors.New("file changed during read")
+)
+
+// Client is safe for concurrent use.
+type Client struct {
+	rpc       pb.FileServiceClient
+	chunkSize int
+	retry     RetryPolicy
+}
+
+type Option func(*Client)
+
+// WithChunkSize fixes the upload chunk size instead of deriving it from the
+// size of each upload.
+func WithChunkSize(n int) Option {
+	return func(c *Client) {
+		c.chunkSize = min(max(n, MinChunkSize), MaxChunkSize)
+	}
+}
+
+// WithRetry replaces the default retry policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// New returns a client that calls the file service over conn. The caller
+// keeps ownership of conn.
+func New(conn grpc.ClientConnInterface, opts ...Option) *Client {
+	c := &Client{
+		rpc:   pb.NewFileServiceClient(conn),
+		retry: DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// FileInfo describes a stored file.
+type FileInfo struct {
+	Name        string
+	Size        int64
+	StoredSize  int64
+	Compression string
+	ETag        string
+	Digest      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresAt   time.Time // zero when the file never expires
+	Attributes  map[string]string
+}
+
+func fromPb(m *pb.FileMetadata) FileInfo {
+	fi := FileInfo{
+		Name:        m.GetName(),
+		Size:        m.GetSize(),
+		StoredSize:  m.GetStoredSize(),
+		Compression: m.GetCompression(),
+		ETag:        m.GetEtag(),
+		Digest:      m.GetDigest(),
+		CreatedAt:   m.GetCreatedAt().AsTime(),
+		UpdatedAt:   m.GetUpdatedAt().AsTime(),
+		Attributes:  m.GetAttributes(),
+	}
+	if m.GetExpiresAt() != nil {
+		fi.ExpiresAt = m.GetExpiresAt().AsTime()
+	}
+	return fi
+}
+
+// List yields the files whose name starts with prefix, in the order the
+// server returns them. The listing is fetched when iteration starts; an error
+// is yielded once, as the last element.
+func (c *Client) List(ctx context.Context, prefix string) iter.Seq2[FileInfo, error] {
+	return func(yield func(FileInfo, error) bool) {
+		var resp *pb.ListFilesResponse
+		err := c.retry.do(ctx, func() error {
+			var err error
+			resp, err = c.rpc.ListFiles(ctx, &pb.ListFilesRequest{})
+			return err
+		})
+		if err != nil {
+			yield(FileInfo{}, fmt.Errorf("list files: %w", err))
+			return
+		}
+		for _, m := range resp.GetFiles() {
+			if !strings.HasPrefix(m.GetName(), prefix) {
+				continue
+			}
+			if !yield(fromPb(m), nil) {
+				return
+			}
+		}
+	}
+}
+
+// Stat returns the metadata of name.
+func (c *Client) Stat(ctx context.Context, name string) (FileInfo, error) {
+	var fi FileInfo
+	err := c.retry.do(ctx, func() error {
+		stream, err := c.rpc.Batch(ctx)
+		if err != nil {
+			return err
+		}
+		op := &pb.BatchRequest_Stat{Stat: &pb.StatOp{Filename: name}}
+		if err := stream.Send(&pb.BatchRequest{Op: op}); err != nil {
+			return err
+		}
+		if err := stream.CloseSend(); err != nil {
+			return err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if code := codes.Code(resp.GetCode()); code != codes.OK {
+			return status.Error(code, resp.GetError())
+		}
+		fi = fromPb(resp.GetStat())
+		return nil
+	})
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("stat %s: %w", name, wrapNotFound(err))
+	}
+	return fi, nil
+}
+
+func wrapNotFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// wrapReadError is wrapNotFound for reads conditioned on an ETag.
+func wrapReadError(err error) error {
+	if status.Code(err) == codes.FailedPrecondition {
+		return fmt.Errorf("%w: %w", ErrChanged, err)
+	}
+	return wrapNotFound(err)
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/PianyCoder/test_file_service/internal/fakeserver"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, opts ...Option) (*Client, *fakeserver.Server) {
+	t.Helper()
+	fake := fakeserver.New()
+	conn := fakeserver.Dial(t, fake)
+	fast := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	return New(conn, append([]Option{WithRetry(fast)}, opts...)...), fake
+}
+
+func stored(fake *fakeserver.Server, name string) []byte {
+	data, _ := fake.Get(name)
+	return data
+}
+
+func testData(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func TestUploadDownload(t *testing.T) {
+	c, fake := newTestClient(t)
+	ctx := context.Background()
+	data := testData(100_000)
+
+	res, err := c.Upload(ctx, "a.bin", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Size != int64(len(data)) || res.VersionID != "v1" {
+		t.Errorf("result = %+v", res)
+	}
+	if !bytes.Equal(stored(fake, "a.bin"), data) {
+		t.Error("stored content differs")
+	}
+
+	var buf bytes.Buffer
+	n, err := c.Download(ctx, "a.bin", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("downloaded %d bytes, content equal: %v", n, bytes.Equal(buf.Bytes(), data))
+	}
+}
+
+func TestUploadChunkSize(t *testing.T) {
+	c, fake := newTestClient(t)
+	// 10 MiB / 100 is above MinChunkSize, so chunks are about 100 KiB.
+	data := testData(10 << 20)
+	if _, err := c.Upload(context.Background(), "big.bin", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fake.ChunkSizes()[0], len(data)/100; got != want {
+		t.Errorf("chunk size = %d, want %d", got, want)
+	}
+
+	c, fake = newTestClient(t, WithChunkSize(1))
+	if _, err := c.Upload(context.Background(), "small.bin", bytes.NewReader(testData(MinChunkSize+1))); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.ChunkSizes()[0]; got != MinChunkSize {
+		t.Errorf("fixed chunk size = %d, want %d", got, MinChunkSize)
+	}
+}
+
+func TestUploadRetry(t *testing.T) {
+	c, fake := newTestClient(t)
+	ctx := context.Background()
+	data := testData(50_000)
+
+	fake.FailUploads = 1
+	if _, err := c.Upload(ctx, "seek.bin", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if fake.UploadCalls() != 2 || !bytes.Equal(stored(fake, "seek.bin"), data) {
+		t.Errorf("calls = %d, content equal: %v", fake.UploadCalls(), bytes.Equal(stored(fake, "seek.bin"), data))
+	}
+
+	// A plain reader cannot be replayed, so the failure is returned.
+	fake.FailUploads = 1
+	_, err := c.Upload(ctx, "stream.bin", io.MultiReader(bytes.NewReader(data)))
+	if status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Errorf("err = %v, want Unavailable", err)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	c, fake := newTestClient(t)
+	data := testData(5000)
+	fake.Put("r.bin", data)
+	fake.FailDownloads = 2
+
+	var buf bytes.Buffer
+	if _, err := c.Download(context.Background(), "r.bin", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("resumed download differs: got %d bytes", buf.Len())
+	}
+}
+
+func TestDownloadChangedDuringResume(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.Put("r.bin", testData(5000))
+	fake.FailDownloads = 1
+	fake.OnFail = func() { fake.Put("r.bin", testData(6000)[1000:]) }
+
+	if _, err := c.Download(context.Background(), "r.bin", io.Discard); !errors.Is(err, ErrChanged) {
+		t.Errorf("err = %v, want ErrChanged", err)
+	}
+}
+
+func TestUploadCreateRetry(t *testing.T) {
+	data := testData(50_000)
+	other := testData(40_000)
+	tests := []struct {
+		name    string
+		setup   func(*fakeserver.Server)
+		wantErr codes.Code
+		want    []byte
+	}{
+		{
+			name:  "response lost",
+			setup: func(f *fakeserver.Server) { f.LoseUploads = 1 },
+			want:  data,
+		},
+		{
+			name: "created by another writer meanwhile",
+			setup: func(f *fakeserver.Server) {
+				f.FailUploads = 1
+				f.OnFail = func() { f.Put("c.bin", other) }
+			},
+			wantErr: codes.AlreadyExists,
+			want:    other,
+		},
+		{
+			name: "same size created by another writer meanwhile",
+			setup: func(f *fakeserver.Server) {
+				f.FailUploads = 1
+				f.OnFail = func() { f.Put("c.bin", append([]byte{1}, data[1:]...)) }
+			},
+			wantErr: codes.AlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newTestClient(t)
+			tt.setup(fake)
+			res, err := c.Upload(context.Background(), "c.bin", bytes.NewReader(data), UploadOptions{IfNoneMatch: "*"})
+			if code := status.Code(errors.Unwrap(err)); code != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.Size != int64(len(data)) {
+				t.Errorf("size = %d, want %d", res.Size, len(data))
+			}
+			if tt.want != nil && !bytes.Equal(stored(fake, "c.bin"), tt.want) {
+				t.Error("stored content differs")
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	if _, err := c.Download(ctx, "missing", io.Discard); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Download err = %v", err)
+	}
+	if _, err := c.Stat(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat err = %v", err)
+	}
+	if _, err := c.Open(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open err = %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	c, fake := newTestClient(t)
+	for _, name := range []string{"a/1", "a/2", "b/1"} {
+		fake.Put(name, []byte(name))
+	}
+
+	var names []string
+	for fi, err := range c.List(context.Background(), "a/") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, fi.Name)
+	}
+	if len(names) != 2 || names[0] != "a/1" || names[1] != "a/2" {
+		t.Errorf("names = %v", names)
+	}
+
+	for range c.List(context.Background(), "") {
+		break // stopping early must not panic
+	}
+}
+
+func TestFileReadAt(t *testing.T) {
+	c, fake := newTestClient(t)
+	data := testData(10_000)
+	fake.Put("f.bin", data)
+
+	f, err := c.Open(context.Background(), "f.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.Size() != int64(len(data)) {
+		t.Errorf("Size = %d", f.Size())
+	}
+
+	p := make([]byte, 2500)
+	n, err := f.ReadAt(p, 3000)
+	if err != nil || n != len(p) || !bytes.Equal(p, data[3000:5500]) {
+		t.Errorf("ReadAt middle: n=%d err=%v", n, err)
+	}
+
+	// The tail is shorter than p.
+	n, err = f.ReadAt(p, 9000)
+	if err != io.EOF || n != 1000 || !bytes.Equal(p[:n], data[9000:]) {
+		t.Errorf("ReadAt tail: n=%d err=%v", n, err)
+	}
+	if n, err := f.ReadAt(p, int64(len(data))); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt end: n=%d err=%v", n, err)
+	}
+
+	// A failure partway through is resumed.
+	fake.FailDownloads = 1
+	n, err = f.ReadAt(p, 0)
+	if err != nil || !bytes.Equal(p[:n], data[:2500]) {
+		t.Errorf("ReadAt after failure: n=%d err=%v", n, err)
+	}
+
+	if _, err := f.Seek(-100, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(f)
+	if err != nil || !bytes.Equal(got, data[len(data)-100:]) {
+		t.Errorf("Seek and Read: %d bytes, err=%v", len(got), err)
+	}
+
+	// Reads stay pinned to the version seen by Open.
+	fake.Put("f.bin", testData(10_001)[1:])
+	if _, err := f.ReadAt(p, 0); !errors.Is(err, ErrChanged) {
+		t.Errorf("ReadAt after overwrite: err = %v, want ErrChanged", err)
+	}
+}
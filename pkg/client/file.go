@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"io"
+	"sync"
+)
+
+// File is a read-only handle to a remote file. Every read is a ranged
+// DownloadFile call, so File suits random access to parts of large files;
+// use Download to fetch a whole file. Reads are pinned to the version seen
+// by Open and fail with ErrChanged once the file is replaced.
+//
+// ReadAt may be called concurrently; Read and Seek share an offset and must
+// not be.
+type File struct {
+	c    *Client
+	ctx  context.Context
+	info FileInfo
+
+	mu     sync.Mutex
+	offset int64
+}
+
+var (
+	_ io.ReaderAt   = (*File)(nil)
+	_ io.ReadSeeker = (*File)(nil)
+)
+
+// Open stats name and returns a handle for reading it. ctx applies to every
+// read made through the handle.
+func (c *Client) Open(ctx context.Context, name string) (*File, error) {
+	info, err := c.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &File{c: c, ctx: ctx, info: info}, nil
+}
+
+func (f *File) Name() string {
+	return f.info.Name
+}
+
+func (f *File) Size() int64 {
+	return f.info.Size
+}
+
+func (f *File) Stat() FileInfo {
+	return f.info
+}
+
+// ReadAt reads len(p) bytes starting at off. As io.ReaderAt requires, it
+// returns io.EOF when fewer bytes are available.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("read %s: negative offset", f.info.Name)
+	}
+	if off >= f.info.Size {
+		return 0, io.EOF
+	}
+	want := min(int64(len(p)), f.info.Size-off)
+	if want == 0 {
+		return 0, nil
+	}
+
+	var n int
+	err := f.c.retry.do(f.ctx, func() error {
+		w := &sliceWriter{buf: p[n:want]}
+		req := &pb.DownloadFileRequest{Filename: f.info.Name, Offset: off + int64(n), Length: want - int64(n), IfMatch: f.info.ETag}
+		m, err := f.c.download(f.ctx, req, w)
+		n += int(m)
+		return err
+	})
+	if err != nil && !errors.Is(err, errShortBuffer) {
+		return n, fmt.Errorf("read %s: %w", f.info.Name, wrapReadError(err))
+	}
+	if int64(n) < int64(len(p)) {
+		// The file is shorter than len(p) from off, or changed under us.
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size
+	default:
+		return 0, fmt.Errorf("seek %s: invalid whence %d", f.info.Name, whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek %s: negative position", f.info.Name)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Close exists for symmetry with os.File; a File holds no resources.
+func (f *File) Close() error {
+	return nil
+}
+
+var errShortBuffer = errors.New("server sent more data than requested")
+
+// sliceWriter fills buf and refuses to grow past it.
+type sliceWriter struct {
+	buf []byte
+	n   int
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	n := copy(w.buf[w.n:], p)
+	w.n += n
+	if n < len(p) {
+		return n, errShortBuffer
+	}
+	return n, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides how often and how patiently calls failing with a
+// transient status are repeated. MaxAttempts of 1 disables retries.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// IsTransient reports whether err is worth retrying: the server or the
+// network was briefly unavailable, or the server aborted the call.
+func IsTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// do runs call until it succeeds, fails permanently, runs out of attempts
+// or ctx is done.
+func (p RetryPolicy) do(ctx context.Context, call func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || !IsTransient(err) || attempt >= p.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		if err := p.sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, p.MaxBackoff)
+	}
+}
+
+// sleep waits between half and one and a half times d, so that clients that
+// failed together do not retry in step.
+func (p RetryPolicy) sleep(ctx context.Context, d time.Duration) error {
+	if d > 0 {
+		d = d/2 + rand.N(d)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
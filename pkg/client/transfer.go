@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	pb "github.com/PianyCoder/test_file_service/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"os"
+)
+
+// UploadOptions mirror the conditional and processing options of the
+// UploadFile RPC.
+type UploadOptions struct {
+	IfNoneMatch string // "*" creates the file only if it does not exist
+	IfMatch     string // overwrite only if the current ETag matches
+	Compression string // "gzip", "zstd", "none" or empty for the server default
+}
+
+type UploadResult struct {
+	Name      string
+	VersionID string
+	Size      int64 // bytes sent
+}
+
+// Upload stores the content of r as name. A transient failure is retried
+// only when r is an io.Seeker, by rewinding it to where the upload began;
+// other readers cannot be replayed and the error is returned. The result of
+// a create that is only known to have succeeded by comparing content has no
+// VersionID.
+func (c *Client) Upload(ctx context.Context, name string, r io.Reader, opts ...UploadOptions) (UploadResult, error) {
+	var o UploadOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	chunkSize := c.uploadChunkSize(r)
+
+	// Only a reader that can be rewound can be sent again.
+	policy := c.retry
+	seeker, _ := r.(io.Seeker)
+	var start int64
+	if seeker != nil {
+		pos, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			seeker = nil
+		}
+		start = pos
+	}
+	if seeker == nil {
+		policy.MaxAttempts = 1
+	}
+
+	var res UploadResult
+	first := true
+	err := policy.do(ctx, func() error {
+		retry := !first
+		if retry {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return fmt.Errorf("rewind for retry: %w", err)
+			}
+		}
+		first = false
+		var err error
+		res, err = c.upload(ctx, name, r, o, chunkSize)
+		// A create whose response was lost fails with AlreadyExists when
+		// retried; it succeeded if the file holds exactly our content.
+		if retry && o.IfNoneMatch == "*" && status.Code(err) == codes.AlreadyExists {
+			size, same, cerr := c.sameContent(ctx, name, r, seeker, start)
+			if cerr != nil {
+				return cerr
+			}
+			if same {
+				res = UploadResult{Name: name, Size: size}
+				return nil
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("upload %s: %w", name, err)
+	}
+	return res, nil
+}
+
+func (c *Client) upload(ctx context.Context, name string, r io.Reader, o UploadOptions, chunkSize int) (UploadResult, error) {
+	// Cancelling rather than closing the stream on a read error keeps the
+	// server from storing a truncated file.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.UploadFile(ctx)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	err = stream.Send(&pb.UploadFileRequest{
+		Filename:    name,
+		IfNoneMatch: o.IfNoneMatch,
+		IfMatch:     o.IfMatch,
+		Compression: o.Compression,
+	})
+	if err != nil && err != io.EOF {
+		return UploadResult{}, err
+	}
+
+	var sent int64
+	buf := make([]byte, chunkSize)
+	for err == nil {
+		n, rerr := io.ReadFull(r, buf)
+		if n > 0 {
+			// io.EOF from Send means the server ended the call;
+			// CloseAndRecv below returns its status.
+			err = stream.Send(&pb.UploadFileRequest{Chunk: buf[:n]})
+			sent += int64(n)
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return UploadResult{}, fmt.Errorf("read source: %w", rerr)
+		}
+	}
+	if err != nil && err != io.EOF {
+		return UploadResult{}, err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return UploadResult{}, err
+	}
+	return UploadResult{Name: name, VersionID: resp.GetVersionId(), Size: sent}, nil
+}
+
+// uploadChunkSize aims at roughly a hundred messages per upload when the
+// size of r can be told, bounded by MinChunkSize and MaxChunkSize.
+func (c *Client) uploadChunkSize(r io.Reader) int {
+	if c.chunkSize > 0 {
+		return c.chunkSize
+	}
+	size := int64(-1)
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		size = int64(v.Len())
+	case interface{ Size() int64 }:
+		size = v.Size()
+	case *os.File:
+		if fi, err := v.Stat(); err == nil && fi.Mode().IsRegular() {
+			size = fi.Size()
+		}
+	}
+	if size < 0 {
+		return DefaultChunkSize
+	}
+	return int(min(max(size/100, MinChunkSize), MaxChunkSize))
+}
+
+// Download writes the content of name to w and returns the number of bytes
+// written. A download interrupted by a transient failure resumes where it
+// stopped, and fails with ErrChanged if the file was replaced meanwhile.
+func (c *Client) Download(ctx context.Context, name string, w io.Writer) (int64, error) {
+	// The ETag pins every call to the version seen first, so that a resumed
+	// download never appends the tail of another version.
+	info, err := c.Stat(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("download %s: %w", name, err)
+	}
+	var written int64
+	err = c.retry.do(ctx, func() error {
+		n, err := c.download(ctx, &pb.DownloadFileRequest{Filename: name, Offset: written, IfMatch: info.ETag}, w)
+		written += n
+		return err
+	})
+	if err != nil {
+		return written, fmt.Errorf("download %s: %w", name, wrapReadError(err))
+	}
+	return written, nil
+}
+
+func (c *Client) download(ctx context.Context, req *pb.DownloadFileRequest, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.rpc.DownloadFile(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	var written int64
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(resp.GetChunk())
+		written += int64(n)
+		if err != nil {
+			return written, fmt.Errorf("write destination: %w", err)
+		}
+	}
+}
+
+var errContentDiffers = errors.New("content differs")
+
+// sameContent reports whether name holds exactly the bytes of r from start,
+// and how many there are.
+func (c *Client) sameContent(ctx context.Context, name string, r io.Reader, seeker io.Seeker, start int64) (int64, bool, error) {
+	info, err := c.Stat(ctx, name)
+	if err != nil {
+		return 0, false, err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return 0, false, fmt.Errorf("rewind for compare: %w", err)
+	}
+	n, err := c.download(ctx, &pb.DownloadFileRequest{Filename: name, IfMatch: info.ETag}, &compareWriter{r: r})
+	if errors.Is(err, errContentDiffers) || status.Code(err) == codes.FailedPrecondition {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if k, _ := io.ReadFull(r, make([]byte, 1)); k > 0 {
+		return 0, false, nil
+	}
+	return n, true, nil
+}
+
+// compareWriter fails as soon as what is written differs from what r holds.
+type compareWriter struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (w *compareWriter) Write(p []byte) (int, error) {
+	if cap(w.buf) < len(p) {
+		w.buf = make([]byte, len(p))
+	}
+	buf := w.buf[:len(p)]
+	if _, err := io.ReadFull(w.r, buf); err != nil || !bytes.Equal(buf, p) {
+		return 0, errContentDiffers
+	}
+	return len(p), nil
+}